@@ -0,0 +1,248 @@
+// Package accounting measures the time that the on call people cover. The covered time is split into business hours,
+// off-hours, weekends and holidays by the working hours, the weekend and the holidays of a room.
+package accounting
+
+import (
+	"time"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+// DateLayout is the layout of the holidays of a calendar.
+const DateLayout = "2006-01-02"
+
+// Coverage is the time that is covered by some shifts. The day counts are derived from the covered time: every day with
+// some covered time counts once, as a working day or a rest day.
+type Coverage struct {
+	BusinessHours time.Duration
+	OffHours      time.Duration
+	WeekendHours  time.Duration
+	// HolidayHours is the time of the holidays, even the ones in the weekend.
+	HolidayHours time.Duration
+	WorkingDays  int
+	RestDays     int
+}
+
+// RestDayHours returns the covered time of the weekends and the holidays.
+func (c Coverage) RestDayHours() time.Duration {
+	return c.WeekendHours + c.HolidayHours
+}
+
+// Total returns the whole covered time.
+func (c Coverage) Total() time.Duration {
+	return c.BusinessHours + c.OffHours + c.RestDayHours()
+}
+
+// Add adds the other coverage to the coverage. The days are added up too, so a day that both of them cover counts
+// twice.
+func (c *Coverage) Add(other Coverage) {
+	c.BusinessHours += other.BusinessHours
+	c.OffHours += other.OffHours
+	c.WeekendHours += other.WeekendHours
+	c.HolidayHours += other.HolidayHours
+	c.WorkingDays += other.WorkingDays
+	c.RestDays += other.RestDays
+}
+
+// Key groups the coverage of each holder by track.
+type Key struct {
+	Track  string
+	Holder string
+}
+
+// Tally measures the time that each holder covered in each track between from and to by the calendar. A day counts once
+// for each holder and track, even if it is covered by several shifts or parts of them.
+func Tally(shifts []model.ShiftReport, from, to, now time.Time, calendar Calendar) map[Key]Coverage {
+	res := make(map[Key]Coverage)
+	days := make(map[Key]map[string]bool)
+
+	for _, shift := range shifts {
+		// Shifts that are not ended yet are counted until now. Scheduled shifts in the future are skipped below.
+		start, end := shift.StartTime, now
+		if shift.EndTime != nil {
+			end = *shift.EndTime
+		}
+
+		if from.After(start) {
+			start = from
+		}
+
+		if to.Before(end) {
+			end = to
+		}
+
+		// Parts of the shifts that are split by overrides may be out of the range.
+		if !start.Before(end) {
+			continue
+		}
+
+		key := Key{Track: shift.Track, Holder: shift.Holders}
+
+		coverage := res[key]
+		coverage.Add(calendar.Split(start, end))
+		res[key] = coverage
+
+		if days[key] == nil {
+			days[key] = make(map[string]bool)
+		}
+
+		for date, rest := range calendar.Days(start, end) {
+			days[key][date] = rest
+		}
+	}
+
+	for key, coverage := range res {
+		coverage.WorkingDays, coverage.RestDays = 0, 0
+
+		for _, rest := range days[key] {
+			if rest {
+				coverage.RestDays++
+			} else {
+				coverage.WorkingDays++
+			}
+		}
+
+		res[key] = coverage
+	}
+
+	return res
+}
+
+// Calendar tells the business hours from the off-hours and the rest days.
+type Calendar struct {
+	// Location is the time zone of the days and the working hours.
+	Location *time.Location
+	// Weekend is the weekly rest days.
+	Weekend []time.Weekday
+	// Holidays is the other rest days (like public holidays) in the date layout.
+	Holidays map[string]bool
+	// WorkStart and WorkEnd are the start and the end of the working hours from the start of a working day.
+	WorkStart time.Duration
+	WorkEnd   time.Duration
+}
+
+// NewCalendar returns the calendar of the room with the given holidays.
+func NewCalendar(room *model.Room, holidays []model.Holiday) (Calendar, error) {
+	loc, err := room.Location()
+	if err != nil {
+		return Calendar{}, err
+	}
+
+	workStart, workEnd := room.WorkTime()
+
+	calendar := Calendar{
+		Location:  loc,
+		Weekend:   room.WeekendDays(),
+		Holidays:  make(map[string]bool),
+		WorkStart: workStart,
+		WorkEnd:   workEnd,
+	}
+
+	for _, holiday := range holidays {
+		calendar.Holidays[holiday.Date.Format(DateLayout)] = true
+	}
+
+	return calendar, nil
+}
+
+// IsWeekend reports whether the day is in the weekend.
+func (c Calendar) IsWeekend(day time.Time) bool {
+	for _, weekendDay := range c.Weekend {
+		if day.Weekday() == weekendDay {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsHoliday reports whether the day is a holiday.
+func (c Calendar) IsHoliday(day time.Time) bool {
+	return c.Holidays[day.Format(DateLayout)]
+}
+
+// Split returns the coverage of the time between start and end. It walks the days of the calendar, so the days are
+// told apart in its location even if the time zone changes its offset.
+func (c Calendar) Split(start, end time.Time) Coverage {
+	var res Coverage
+
+	c.walk(start, end, func(day, from, to time.Time) {
+		covered := to.Sub(from)
+
+		switch {
+		case c.IsHoliday(day):
+			res.HolidayHours += covered
+			res.RestDays++
+
+			return
+		case c.IsWeekend(day):
+			res.WeekendHours += covered
+			res.RestDays++
+
+			return
+		}
+
+		business := overlap(from, to, c.at(day, c.WorkStart), c.at(day, c.WorkEnd))
+
+		res.BusinessHours += business
+		res.OffHours += covered - business
+		res.WorkingDays++
+	})
+
+	return res
+}
+
+// Days returns the days that have some time between start and end in the date layout, and whether each of them is a
+// rest day.
+func (c Calendar) Days(start, end time.Time) map[string]bool {
+	res := make(map[string]bool)
+
+	c.walk(start, end, func(day, _, _ time.Time) {
+		res[day.Format(DateLayout)] = c.IsHoliday(day) || c.IsWeekend(day)
+	})
+
+	return res
+}
+
+// walk calls fn with the start of every day between start and end and the part of the time that is in that day.
+func (c Calendar) walk(start, end time.Time, fn func(day, from, to time.Time)) {
+	start, end = start.In(c.Location), end.In(c.Location)
+
+	for day := c.at(start, 0); day.Before(end); day = day.AddDate(0, 0, 1) {
+		from, to := later(start, day), earlier(end, day.AddDate(0, 0, 1))
+		if from.Before(to) {
+			fn(day, from, to)
+		}
+	}
+}
+
+// at returns the time of the day that is the given offset after its start by the clock.
+func (c Calendar) at(day time.Time, offset time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(),
+		int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, c.Location)
+}
+
+func overlap(start, end, windowStart, windowEnd time.Time) time.Duration {
+	start, end = later(start, windowStart), earlier(end, windowEnd)
+	if !start.Before(end) {
+		return 0
+	}
+
+	return end.Sub(start)
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
+
+func earlier(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,263 @@
+package accounting_test
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // Imported for the time zones of the tests
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/accounting"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+func berlin(t *testing.T) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("error loading time zone: %s", err)
+	}
+
+	return loc
+}
+
+func newCalendar(loc *time.Location, weekend ...time.Weekday) accounting.Calendar {
+	return accounting.Calendar{
+		Location:  loc,
+		Weekend:   weekend,
+		Holidays:  map[string]bool{"2022-10-03": true},
+		WorkStart: 9 * time.Hour,
+		WorkEnd:   17 * time.Hour,
+	}
+}
+
+func TestCalendarSplit(t *testing.T) {
+	t.Parallel()
+
+	loc := berlin(t)
+	at := func(value string) time.Time {
+		res, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+		if err != nil {
+			t.Fatalf("invalid time %s: %s", value, err)
+		}
+
+		return res
+	}
+
+	weekend := newCalendar(loc, time.Saturday, time.Sunday)
+
+	tests := []struct {
+		name     string
+		calendar accounting.Calendar
+		start    time.Time
+		end      time.Time
+		expected accounting.Coverage
+	}{
+		{
+			name:     "working day",
+			calendar: weekend,
+			start:    at("2022-10-10 08:00"),
+			end:      at("2022-10-10 18:00"),
+			expected: accounting.Coverage{BusinessHours: 8 * time.Hour, OffHours: 2 * time.Hour, WorkingDays: 1},
+		},
+		{
+			name:     "working day into the weekend",
+			calendar: weekend,
+			start:    at("2022-10-14 16:00"),
+			end:      at("2022-10-15 02:00"),
+			expected: accounting.Coverage{
+				BusinessHours: time.Hour, OffHours: 7 * time.Hour, WeekendHours: 2 * time.Hour,
+				WorkingDays: 1, RestDays: 1,
+			},
+		},
+		{
+			name:     "holiday",
+			calendar: weekend,
+			start:    at("2022-10-03 10:00"),
+			end:      at("2022-10-03 12:00"),
+			expected: accounting.Coverage{HolidayHours: 2 * time.Hour, RestDays: 1},
+		},
+		{
+			name:     "whole week",
+			calendar: weekend,
+			start:    at("2022-10-10 00:00"),
+			end:      at("2022-10-17 00:00"),
+			expected: accounting.Coverage{
+				BusinessHours: 40 * time.Hour, OffHours: 80 * time.Hour, WeekendHours: 48 * time.Hour,
+				WorkingDays: 5, RestDays: 2,
+			},
+		},
+		{
+			name:     "weekend day with the clocks going back",
+			calendar: weekend,
+			start:    at("2022-10-30 00:00"),
+			end:      at("2022-10-31 00:00"),
+			expected: accounting.Coverage{WeekendHours: 25 * time.Hour, RestDays: 1},
+		},
+		{
+			name:     "weekend day with the clocks going forward",
+			calendar: weekend,
+			start:    at("2022-03-27 00:00"),
+			end:      at("2022-03-28 00:00"),
+			expected: accounting.Coverage{WeekendHours: 23 * time.Hour, RestDays: 1},
+		},
+		{
+			name:     "working day with the clocks going back",
+			calendar: newCalendar(loc),
+			start:    at("2022-10-30 00:00"),
+			end:      at("2022-10-31 00:00"),
+			expected: accounting.Coverage{BusinessHours: 8 * time.Hour, OffHours: 17 * time.Hour, WorkingDays: 1},
+		},
+		{
+			name:     "times in another time zone",
+			calendar: weekend,
+			start:    at("2022-10-10 08:00").UTC(),
+			end:      at("2022-10-10 10:00").UTC(),
+			expected: accounting.Coverage{BusinessHours: time.Hour, OffHours: time.Hour, WorkingDays: 1},
+		},
+		{
+			name:     "empty",
+			calendar: weekend,
+			start:    at("2022-10-10 08:00"),
+			end:      at("2022-10-10 08:00"),
+			expected: accounting.Coverage{},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := test.calendar.Split(test.start, test.end); got != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestTally(t *testing.T) {
+	t.Parallel()
+
+	loc := berlin(t)
+	at := func(value string) *time.Time {
+		res, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+		if err != nil {
+			t.Fatalf("invalid time %s: %s", value, err)
+		}
+
+		return &res
+	}
+
+	shift := func(holder, track, start, end string) model.ShiftReport {
+		res := model.ShiftReport{ID: 1, Holders: holder, Track: track, StartTime: *at(start), EndTime: nil}
+		if end != "" {
+			res.EndTime = at(end)
+		}
+
+		return res
+	}
+
+	calendar := newCalendar(loc, time.Saturday, time.Sunday)
+	a := accounting.Key{Track: model.DefaultTrack, Holder: "@a:example.com"}
+	b := accounting.Key{Track: model.DefaultTrack, Holder: "@b:example.com"}
+	database := accounting.Key{Track: "database", Holder: a.Holder}
+
+	tests := []struct {
+		name     string
+		shifts   []model.ShiftReport
+		from     string
+		to       string
+		now      string
+		expected map[accounting.Key]accounting.Coverage
+	}{
+		{
+			name: "pieces of an override count the day once",
+			shifts: []model.ShiftReport{
+				shift(a.Holder, a.Track, "2022-10-10 09:00", "2022-10-10 12:00"),
+				shift(b.Holder, b.Track, "2022-10-10 12:00", "2022-10-10 14:00"),
+				shift(a.Holder, a.Track, "2022-10-10 14:00", "2022-10-11 09:00"),
+			},
+			from: "2022-10-01 00:00",
+			to:   "2022-11-01 00:00",
+			now:  "2022-10-20 00:00",
+			expected: map[accounting.Key]accounting.Coverage{
+				a: {BusinessHours: 6 * time.Hour, OffHours: 16 * time.Hour, WorkingDays: 2},
+				b: {BusinessHours: 2 * time.Hour, WorkingDays: 1},
+			},
+		},
+		{
+			name: "shifts of the same day count the day once",
+			shifts: []model.ShiftReport{
+				shift(a.Holder, a.Track, "2022-10-15 09:00", "2022-10-15 10:00"),
+				shift(a.Holder, a.Track, "2022-10-15 15:00", "2022-10-15 16:00"),
+			},
+			from: "2022-10-01 00:00",
+			to:   "2022-11-01 00:00",
+			now:  "2022-10-20 00:00",
+			expected: map[accounting.Key]accounting.Coverage{
+				a: {WeekendHours: 2 * time.Hour, RestDays: 1},
+			},
+		},
+		{
+			name: "tracks are counted apart",
+			shifts: []model.ShiftReport{
+				shift(a.Holder, a.Track, "2022-10-10 09:00", "2022-10-10 10:00"),
+				shift(database.Holder, database.Track, "2022-10-10 09:00", "2022-10-10 10:00"),
+			},
+			from: "2022-10-01 00:00",
+			to:   "2022-11-01 00:00",
+			now:  "2022-10-20 00:00",
+			expected: map[accounting.Key]accounting.Coverage{
+				a:        {BusinessHours: time.Hour, WorkingDays: 1},
+				database: {BusinessHours: time.Hour, WorkingDays: 1},
+			},
+		},
+		{
+			name: "shifts are clamped to the range and now",
+			shifts: []model.ShiftReport{
+				shift(a.Holder, a.Track, "2022-09-30 22:00", "2022-10-01 02:00"),
+				shift(b.Holder, b.Track, "2022-10-10 22:00", ""),
+			},
+			from: "2022-10-01 00:00",
+			to:   "2022-11-01 00:00",
+			now:  "2022-10-11 02:00",
+			expected: map[accounting.Key]accounting.Coverage{
+				a: {WeekendHours: 2 * time.Hour, RestDays: 1},
+				b: {OffHours: 4 * time.Hour, WorkingDays: 2},
+			},
+		},
+		{
+			name: "shifts out of the range and scheduled shifts are skipped",
+			shifts: []model.ShiftReport{
+				shift(a.Holder, a.Track, "2022-09-10 09:00", "2022-09-10 10:00"),
+				shift(b.Holder, b.Track, "2022-10-25 09:00", ""),
+				shift(b.Holder, b.Track, "2022-11-01 09:00", "2022-11-01 10:00"),
+			},
+			from:     "2022-10-01 00:00",
+			to:       "2022-11-01 00:00",
+			now:      "2022-10-20 00:00",
+			expected: map[accounting.Key]accounting.Coverage{},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := accounting.Tally(test.shifts, *at(test.from), *at(test.to), *at(test.now), calendar)
+
+			if len(got) != len(test.expected) {
+				t.Fatalf("expected %d keys, got %+v", len(test.expected), got)
+			}
+
+			for key, expected := range test.expected {
+				if got[key] != expected {
+					t.Errorf("expected %+v for %+v, got %+v", expected, key, got[key])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package holiday
+
+import (
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/config"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/database"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/ical"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+const (
+	flagFile = "file"
+	flagRoom = "room"
+)
+
+var ErrFlags = errors.New("error parsing flags")
+
+func main(path, roomID string, cfg config.Database) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, errors.Wrap(err, "error opening calendar")
+	}
+
+	defer file.Close()
+
+	events, err := ical.Parse(file, time.Local)
+	if err != nil {
+		return 0, errors.Wrap(err, "error parsing calendar")
+	}
+
+	oncallDB := database.WithRetry(database.Create, cfg)
+
+	sqlDB, err := oncallDB.DB()
+	if err != nil {
+		logrus.WithError(err).Fatal("error in accessing sql DB instance")
+	}
+
+	defer func() {
+		if err := sqlDB.Close(); err != nil {
+			logrus.Errorf("db connection close error: %s", err.Error())
+		}
+	}()
+
+	holidays := model.CalendarHolidays(roomID, events)
+	holidayRepo := &model.SQLHolidayRepo{DB: oncallDB}
+
+	if err := holidayRepo.Save(holidays); err != nil {
+		return 0, errors.Wrap(err, "error saving holidays")
+	}
+
+	return len(holidays), nil
+}
+
+// Register registers the holidays command, which imports the holidays of a room or the organization from an .ics file.
+func Register(root *cobra.Command, cfg config.Config) {
+	cmd := &cobra.Command{
+		Use:   "holidays",
+		Short: "Imports holidays from an iCalendar (.ics) file",
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			path, err := cmd.Flags().GetString(flagFile)
+			if err != nil || path == "" {
+				return ErrFlags
+			}
+
+			return nil
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := cmd.Flags().GetString(flagFile)
+			if err != nil {
+				return errors.Wrap(err, "error getting file")
+			}
+
+			roomID, err := cmd.Flags().GetString(flagRoom)
+			if err != nil {
+				return errors.Wrap(err, "error getting room")
+			}
+
+			count, err := main(path, roomID, cfg.Database)
+			if err != nil {
+				return errors.Wrap(err, "error running main")
+			}
+
+			cmd.Printf("%d holidays imported\n", count)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP(flagFile, "f", "", "iCalendar file path")
+	cmd.Flags().StringP(flagRoom, "r", model.OrganizationHolidays,
+		"id of the room of the holidays, the holidays of the organization if empty")
+
+	root.AddCommand(cmd)
+}
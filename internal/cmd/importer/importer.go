@@ -0,0 +1,160 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/config"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/database"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/schedule"
+)
+
+const (
+	flagFile    = "file"
+	flagRoom    = "room"
+	flagConfirm = "confirm"
+
+	timeLayout = "2006-01-02T15:04 MST"
+)
+
+var ErrFlags = errors.New("error parsing flags")
+
+// main previews the shifts of the file, or schedules them in the room on behalf of the bot if it's confirmed.
+func main(out io.Writer, path, roomID string, confirm bool, cfg config.Config) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "error reading file")
+	}
+
+	oncallDB := database.WithRetry(database.Create, cfg.Database)
+
+	sqlDB, err := oncallDB.DB()
+	if err != nil {
+		logrus.WithError(err).Fatal("error in accessing sql DB instance")
+	}
+
+	defer func() {
+		if err := sqlDB.Close(); err != nil {
+			logrus.Errorf("db connection close error: %s", err.Error())
+		}
+	}()
+
+	roomRepo := &model.SQLRoomRepo{DB: oncallDB}
+
+	room, err := roomRepo.Find(roomID)
+	if err != nil {
+		return errors.Wrap(err, "error getting room")
+	}
+
+	loc, err := room.Location()
+	if err != nil {
+		return err
+	}
+
+	shifts, err := schedule.Parse(filepath.Base(path), content, roomID, cfg.Matrix.UserID, loc)
+	if err != nil {
+		return errors.Wrap(err, "error parsing file")
+	}
+
+	importer := &schedule.Importer{ShiftRepo: &model.SQLShiftRepo{DB: oncallDB}}
+
+	proposals, err := importer.Preview(shifts, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "error previewing shifts")
+	}
+
+	if confirm {
+		count, err := importer.Commit(proposals)
+		if err != nil {
+			return errors.Wrap(err, "error importing shifts")
+		}
+
+		fmt.Fprintf(out, "%d shifts scheduled and %d skipped\n", count, len(proposals)-count)
+
+		return nil
+	}
+
+	valid := 0
+
+	for n, proposal := range proposals {
+		status := "ok"
+
+		switch {
+		case len(proposal.Conflicts) > 0:
+			status = fmt.Sprintf("skipped: conflicts with the shifts with ids: %v", proposal.Conflicts)
+		case proposal.Problem != "":
+			status = "skipped: " + proposal.Problem
+		default:
+			valid++
+		}
+
+		fmt.Fprintf(out, "%d.\t%s - %s\t%s\t%s\t%s\n", n+1,
+			proposal.Shift.StartTime.In(loc).Format(timeLayout), proposal.Shift.PlannedEndTime.In(loc).Format(timeLayout),
+			strings.Join(proposal.Shift.HolderIDs(), " "), proposal.Shift.Track, status)
+	}
+
+	fmt.Fprintf(out, "%d shifts are going to be scheduled and %d are skipped, run again with --%s to schedule them\n",
+		valid, len(proposals)-valid, flagConfirm)
+
+	return nil
+}
+
+// Register registers the import-shifts command, which schedules the shifts of a .csv or an .ics file in a room.
+func Register(root *cobra.Command, cfg config.Config) {
+	cmd := &cobra.Command{
+		Use:   "import-shifts",
+		Short: "Previews the shifts of a CSV or an iCalendar (.ics) file, and schedules them when confirmed",
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			path, err := cmd.Flags().GetString(flagFile)
+			if err != nil || path == "" {
+				return ErrFlags
+			}
+
+			roomID, err := cmd.Flags().GetString(flagRoom)
+			if err != nil || roomID == "" {
+				return ErrFlags
+			}
+
+			return nil
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := cmd.Flags().GetString(flagFile)
+			if err != nil {
+				return errors.Wrap(err, "error getting file")
+			}
+
+			roomID, err := cmd.Flags().GetString(flagRoom)
+			if err != nil {
+				return errors.Wrap(err, "error getting room")
+			}
+
+			confirm, err := cmd.Flags().GetBool(flagConfirm)
+			if err != nil {
+				return errors.Wrap(err, "error getting confirm")
+			}
+
+			if err := main(cmd.OutOrStdout(), path, roomID, confirm, cfg); err != nil {
+				return errors.Wrap(err, "error running main")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP(flagFile, "f", "", "CSV (start,end,holder[,tier][,track]) or iCalendar file path")
+	cmd.Flags().StringP(flagRoom, "r", "", "id of the room of the shifts")
+	cmd.Flags().Bool(flagConfirm, false, "schedule the shifts instead of previewing them")
+
+	root.AddCommand(cmd)
+}
@@ -0,0 +1,161 @@
+package pay
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/compensation"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/config"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/database"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+const (
+	flagRoom = "room"
+	flagFrom = "from"
+	flagTo   = "to"
+
+	dateLayout = "2006-01-02"
+)
+
+var ErrFlags = errors.New("error parsing flags")
+
+func main(out io.Writer, roomID, fromValue, toValue string, cfg config.Config) error {
+	oncallDB := database.WithRetry(database.Create, cfg.Database)
+
+	sqlDB, err := oncallDB.DB()
+	if err != nil {
+		logrus.WithError(err).Fatal("error in accessing sql DB instance")
+	}
+
+	defer func() {
+		if err := sqlDB.Close(); err != nil {
+			logrus.Errorf("db connection close error: %s", err.Error())
+		}
+	}()
+
+	roomRepo := &model.SQLRoomRepo{DB: oncallDB}
+
+	room, err := roomRepo.Find(roomID)
+	if err != nil {
+		return errors.Wrap(err, "error getting room")
+	}
+
+	loc, err := room.Location()
+	if err != nil {
+		return err
+	}
+
+	from, to, err := parseRange(fromValue, toValue, time.Now().In(loc))
+	if err != nil {
+		return err
+	}
+
+	calculator := &compensation.Calculator{
+		RoomRepo:     roomRepo,
+		ShiftRepo:    &model.SQLShiftRepo{DB: oncallDB},
+		OverrideRepo: &model.SQLOverrideRepo{DB: oncallDB},
+		HolidayRepo:  &model.SQLHolidayRepo{DB: oncallDB},
+		Rates:        compensation.NewRates(cfg.Compensation),
+	}
+
+	payroll, err := calculator.Payroll(roomID, from, to, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "error calculating pays")
+	}
+
+	fmt.Fprintf(out, "rates: %s\n", payroll.Rates)
+
+	for _, pay := range payroll.Pays {
+		fmt.Fprintf(out, "%s\t%s\t(business hours: %s, off-hours: %s, weekends: %s, holidays: %s)\n",
+			pay.Holder, payroll.Rates.Format(pay.Amount), pay.Coverage.BusinessHours, pay.Coverage.OffHours,
+			pay.Coverage.WeekendHours, pay.Coverage.HolidayHours)
+	}
+
+	fmt.Fprintf(out, "total: %s\n", payroll.Rates.Format(payroll.Total()))
+
+	return nil
+}
+
+// parseRange parses the first and the last days of the pays in the time zone of now, which is the time zone of the
+// room. The last day is included. The pays are calculated from the start of this month until now by default.
+func parseRange(fromValue, toValue string, now time.Time) (time.Time, time.Time, error) {
+	loc := now.Location()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	to := now
+
+	if fromValue != "" {
+		day, err := time.ParseInLocation(dateLayout, fromValue, loc)
+		if err != nil {
+			return from, to, errors.Wrap(err, "invalid from day")
+		}
+
+		from = day
+	}
+
+	if toValue != "" {
+		day, err := time.ParseInLocation(dateLayout, toValue, loc)
+		if err != nil {
+			return from, to, errors.Wrap(err, "invalid to day")
+		}
+
+		to = day.AddDate(0, 0, 1)
+	}
+
+	if !from.Before(to) {
+		return from, to, errors.New("from day is after to day")
+	}
+
+	return from, to, nil
+}
+
+// Register registers the pay command, which calculates the pays of the on call people of a room.
+func Register(root *cobra.Command, cfg config.Config) {
+	cmd := &cobra.Command{
+		Use:   "pay",
+		Short: "Calculates the pays of the on call people of a room by the hourly rates",
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			roomID, err := cmd.Flags().GetString(flagRoom)
+			if err != nil || roomID == "" {
+				return ErrFlags
+			}
+
+			return nil
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			roomID, err := cmd.Flags().GetString(flagRoom)
+			if err != nil {
+				return errors.Wrap(err, "error getting room")
+			}
+
+			fromValue, err := cmd.Flags().GetString(flagFrom)
+			if err != nil {
+				return errors.Wrap(err, "error getting from")
+			}
+
+			toValue, err := cmd.Flags().GetString(flagTo)
+			if err != nil {
+				return errors.Wrap(err, "error getting to")
+			}
+
+			if err := main(cmd.OutOrStdout(), roomID, fromValue, toValue, cfg); err != nil {
+				return errors.Wrap(err, "error running main")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP(flagRoom, "r", "", "id of the room")
+	cmd.Flags().String(flagFrom, "", "first day of the pays (yyyy-mm-dd), the start of this month if empty")
+	cmd.Flags().String(flagTo, "", "last day of the pays (yyyy-mm-dd), included, now if empty")
+
+	root.AddCommand(cmd)
+}
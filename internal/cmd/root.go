@@ -3,7 +3,10 @@ package cmd
 import (
 	"github.com/spf13/cobra"
 
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/cmd/holiday"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/cmd/importer"
 	"github.com/snapp-incubator/matrix-on-call-bot/internal/cmd/migrate"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/cmd/pay"
 	"github.com/snapp-incubator/matrix-on-call-bot/internal/cmd/server"
 	"github.com/snapp-incubator/matrix-on-call-bot/internal/config"
 )
@@ -18,6 +21,9 @@ func NewRootCommand() *cobra.Command {
 
 	server.Register(root, cfg)
 	migrate.Register(root, cfg)
+	holiday.Register(root, cfg)
+	pay.Register(root, cfg)
+	importer.Register(root, cfg)
 
 	return root
 }
@@ -1,20 +1,30 @@
 package server
 
 import (
+	"context"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/compensation"
 	"github.com/snapp-incubator/matrix-on-call-bot/internal/config"
 	"github.com/snapp-incubator/matrix-on-call-bot/internal/database"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/feed"
 	"github.com/snapp-incubator/matrix-on-call-bot/internal/matrix"
 	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
 )
 
-const sigChanSize = 2
+const (
+	sigChanSize = 2
+	// shutdownTimeout is how long the HTTP server waits for the requests in progress when it's stopped.
+	shutdownTimeout = 5 * time.Second
+)
 
 func main(cfg config.Config) {
 	oncallDB := database.WithRetry(database.Create, cfg.Database)
@@ -22,9 +32,21 @@ func main(cfg config.Config) {
 	roomRepo := &model.SQLRoomRepo{DB: oncallDB}
 	shiftRepo := &model.SQLShiftRepo{DB: oncallDB}
 	followUpRepo := &model.SQLFollowUpRepo{DB: oncallDB}
-
-	bot, err := matrix.New(cfg.Matrix.URL, cfg.Matrix.UserID, cfg.Matrix.Token,
-		cfg.Matrix.DisplayName, roomRepo, shiftRepo, followUpRepo)
+	rotationRepo := &model.SQLRotationRepo{DB: oncallDB}
+	overrideRepo := &model.SQLOverrideRepo{DB: oncallDB}
+	swapRepo := &model.SQLSwapRepo{DB: oncallDB}
+	noteRepo := &model.SQLNoteRepo{DB: oncallDB}
+	shiftEditRepo := &model.SQLShiftEditRepo{DB: oncallDB}
+	reminderRepo := &model.SQLReminderRepo{DB: oncallDB}
+	absenceRepo := &model.SQLAbsenceRepo{DB: oncallDB}
+	preferenceRepo := &model.SQLPreferenceRepo{DB: oncallDB}
+	holidayRepo := &model.SQLHolidayRepo{DB: oncallDB}
+	calendarTokenRepo := &model.SQLCalendarTokenRepo{DB: oncallDB}
+
+	bot, err := matrix.New(cfg.Matrix.URL, cfg.Matrix.UserID, cfg.Matrix.Token, cfg.Matrix.DisplayName,
+		roomRepo, shiftRepo, followUpRepo, rotationRepo, overrideRepo, swapRepo, noteRepo,
+		shiftEditRepo, reminderRepo, absenceRepo, preferenceRepo, holidayRepo, calendarTokenRepo,
+		compensation.NewRates(cfg.Compensation), cfg.HTTP.PublicURL)
 	if err != nil {
 		logrus.WithField("error", err.Error()).Error("cannot create bot instance")
 	}
@@ -39,12 +61,32 @@ func main(cfg config.Config) {
 
 	logrus.Info("bot is started!")
 	bot.Run()
+	bot.Schedule(cfg.Scheduler.Interval)
+
+	feedServer := feed.NewServer(cfg.HTTP.Address, calendarTokenRepo, shiftRepo)
+
+	go func() {
+		logrus.WithField("address", cfg.HTTP.Address).Info("calendar feeds are served!")
+
+		if err := feedServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.WithError(err).Fatal("error in serving calendar feeds")
+		}
+	}()
 
 	<-sigChan
 
 	logrus.Info("stopping bot loop!")
 	bot.Stop()
 
+	logrus.Info("stopping calendar feeds server!")
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := feedServer.Shutdown(ctx); err != nil {
+		logrus.WithError(err).Error("error in stopping calendar feeds server")
+	}
+
 	logrus.Info("closing DB connections!")
 
 	sqlDB, err := oncallDB.DB()
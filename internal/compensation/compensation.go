@@ -0,0 +1,241 @@
+// Package compensation calculates how much the on call people are paid for the time they covered. Each hour is paid by
+// the rate of its kind: business hours, off-hours of the working days, weekends or holidays.
+package compensation
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/accounting"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/config"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+const (
+	BusinessHours = "business_hours"
+	OffHours      = "off_hours"
+	Weekend       = "weekend"
+	Holiday       = "holiday"
+	Currency      = "currency"
+
+	// RateSeparator separates the rules of the rates of a room.
+	RateSeparator = " "
+	// RuleSeparator separates the name of a rule from its value.
+	RuleSeparator = "="
+
+	cents = 100
+)
+
+// Rates is the amount that is paid for an hour of each kind of the covered time.
+type Rates struct {
+	Currency      string
+	BusinessHours float64
+	OffHours      float64
+	Weekend       float64
+	// Holiday is the rate of the holidays, even the ones in the weekend.
+	Holiday float64
+}
+
+// NewRates returns the default rates of the configuration.
+func NewRates(cfg config.Compensation) Rates {
+	return Rates{
+		Currency:      cfg.Currency,
+		BusinessHours: cfg.BusinessHours,
+		OffHours:      cfg.OffHours,
+		Weekend:       cfg.Weekend,
+		Holiday:       cfg.Holiday,
+	}
+}
+
+// ParseRates parses rate rules like "off_hours=10 weekend=20 currency=EUR" over the given rates. The rules that are not
+// given keep their value.
+func ParseRates(value string, rates Rates) (Rates, error) {
+	for _, rule := range strings.Fields(value) {
+		name, amount, ok := strings.Cut(rule, RuleSeparator)
+		if !ok {
+			return rates, errors.Errorf("invalid rate %s, use name=amount", rule)
+		}
+
+		name = strings.ToLower(name)
+
+		if name == Currency {
+			rates.Currency = amount
+
+			continue
+		}
+
+		rate, err := strconv.ParseFloat(amount, 64)
+		if err != nil || rate < 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+			return rates, errors.Errorf("invalid amount of rate %s", rule)
+		}
+
+		switch name {
+		case BusinessHours:
+			rates.BusinessHours = rate
+		case OffHours:
+			rates.OffHours = rate
+		case Weekend:
+			rates.Weekend = rate
+		case Holiday:
+			rates.Holiday = rate
+		default:
+			return rates, errors.Errorf("unknown rate %s, use %s, %s, %s, %s or %s",
+				name, BusinessHours, OffHours, Weekend, Holiday, Currency)
+		}
+	}
+
+	return rates, nil
+}
+
+// RoomRates returns the rates of the room over the default rates.
+func RoomRates(room *model.Room, defaults Rates) (Rates, error) {
+	rates, err := ParseRates(room.Rates, defaults)
+	if err != nil {
+		return rates, errors.Wrap(err, "invalid rates of room")
+	}
+
+	return rates, nil
+}
+
+func (r Rates) String() string {
+	rules := []string{
+		BusinessHours + RuleSeparator + formatRate(r.BusinessHours),
+		OffHours + RuleSeparator + formatRate(r.OffHours),
+		Weekend + RuleSeparator + formatRate(r.Weekend),
+		Holiday + RuleSeparator + formatRate(r.Holiday),
+	}
+
+	if r.Currency != "" {
+		rules = append(rules, Currency+RuleSeparator+r.Currency)
+	}
+
+	return strings.Join(rules, RateSeparator)
+}
+
+// Amount returns the amount that is paid for the coverage.
+func (r Rates) Amount(coverage accounting.Coverage) float64 {
+	amount := coverage.BusinessHours.Hours()*r.BusinessHours +
+		coverage.OffHours.Hours()*r.OffHours +
+		coverage.WeekendHours.Hours()*r.Weekend +
+		coverage.HolidayHours.Hours()*r.Holiday
+
+	return round(amount)
+}
+
+// Format formats an amount in the currency of the rates.
+func (r Rates) Format(amount float64) string {
+	if r.Currency == "" {
+		return formatRate(amount)
+	}
+
+	return fmt.Sprintf("%s %s", formatRate(amount), r.Currency)
+}
+
+func formatRate(rate float64) string {
+	return strconv.FormatFloat(rate, 'f', -1, 64)
+}
+
+// Pay is the amount that is paid to a holder for the time they covered in all the tracks.
+type Pay struct {
+	Holder   string
+	Coverage accounting.Coverage
+	Amount   float64
+}
+
+// Payroll is the pays of the holders of a room in a period.
+type Payroll struct {
+	Rates Rates
+	Pays  []Pay
+}
+
+// Total returns the amount that is paid to all the holders.
+func (p Payroll) Total() float64 {
+	var res float64
+
+	for _, pay := range p.Pays {
+		res += pay.Amount
+	}
+
+	return round(res)
+}
+
+// round rounds an amount to cents.
+func round(amount float64) float64 {
+	return math.Round(amount*cents) / cents
+}
+
+// Calculate pays the holders by the rates for the coverage of the shifts. The pays are sorted by holder.
+func Calculate(coverages map[accounting.Key]accounting.Coverage, rates Rates) Payroll {
+	holders := make(map[string]accounting.Coverage)
+
+	for key, coverage := range coverages {
+		total := holders[key.Holder]
+		total.Add(coverage)
+		holders[key.Holder] = total
+	}
+
+	res := Payroll{Rates: rates, Pays: make([]Pay, 0, len(holders))}
+
+	for holder, coverage := range holders {
+		res.Pays = append(res.Pays, Pay{Holder: holder, Coverage: coverage, Amount: rates.Amount(coverage)})
+	}
+
+	sort.Slice(res.Pays, func(i, j int) bool {
+		return res.Pays[i].Holder < res.Pays[j].Holder
+	})
+
+	return res
+}
+
+// Calculator calculates the payroll of a room from its shifts, overrides, holidays and rates.
+type Calculator struct {
+	RoomRepo     model.RoomRepo
+	ShiftRepo    model.ShiftRepo
+	OverrideRepo model.OverrideRepo
+	HolidayRepo  model.HolidayRepo
+	// Rates is the default rates of the rooms that have not set their own.
+	Rates Rates
+}
+
+// Payroll returns the payroll of the room between from and to. Shifts that are not ended yet are paid until now.
+func (c *Calculator) Payroll(roomID string, from, to, now time.Time) (Payroll, error) {
+	room, err := c.RoomRepo.Find(roomID)
+	if err != nil {
+		return Payroll{}, errors.Wrap(err, "error getting room")
+	}
+
+	rates, err := RoomRates(room, c.Rates)
+	if err != nil {
+		return Payroll{}, err
+	}
+
+	shifts, err := c.ShiftRepo.Report(roomID, from, to)
+	if err != nil {
+		return Payroll{}, errors.Wrap(err, "error getting shifts")
+	}
+
+	overrides, err := c.OverrideRepo.Report(roomID, from, to)
+	if err != nil {
+		return Payroll{}, errors.Wrap(err, "error getting overrides")
+	}
+
+	holidays, err := c.HolidayRepo.Get(roomID, from, to)
+	if err != nil {
+		return Payroll{}, errors.Wrap(err, "error getting holidays")
+	}
+
+	calendar, err := accounting.NewCalendar(room, holidays)
+	if err != nil {
+		return Payroll{}, errors.Wrap(err, "error creating calendar")
+	}
+
+	coverages := accounting.Tally(model.ApplyOverrides(shifts, overrides), from, to, now, calendar)
+
+	return Calculate(coverages, rates), nil
+}
@@ -0,0 +1,148 @@
+package compensation_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/accounting"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/compensation"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+func TestParseRates(t *testing.T) {
+	t.Parallel()
+
+	defaults := compensation.Rates{Currency: "USD", BusinessHours: 1, OffHours: 2, Weekend: 3, Holiday: 4}
+
+	tests := []struct {
+		name     string
+		value    string
+		expected compensation.Rates
+		err      bool
+	}{
+		{
+			name:     "empty",
+			value:    "",
+			expected: defaults,
+		},
+		{
+			name:     "some rules",
+			value:    "off_hours=10 Weekend=20.5",
+			expected: compensation.Rates{Currency: "USD", BusinessHours: 1, OffHours: 10, Weekend: 20.5, Holiday: 4},
+		},
+		{
+			name:     "all rules",
+			value:    "business_hours=0 off_hours=5 weekend=6 holiday=7 currency=EUR",
+			expected: compensation.Rates{Currency: "EUR", BusinessHours: 0, OffHours: 5, Weekend: 6, Holiday: 7},
+		},
+		{name: "unknown rule", value: "night=10", err: true},
+		{name: "negative amount", value: "weekend=-1", err: true},
+		{name: "not a number", value: "weekend=NaN", err: true},
+		{name: "infinite amount", value: "weekend=Inf", err: true},
+		{name: "invalid amount", value: "weekend=ten", err: true},
+		{name: "no separator", value: "weekend", err: true},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := compensation.ParseRates(test.value, defaults)
+			if test.err {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	t.Parallel()
+
+	rates := compensation.Rates{Currency: "EUR", BusinessHours: 1.5, OffHours: 2, Weekend: 3, Holiday: 4}
+
+	tests := []struct {
+		name      string
+		coverages map[accounting.Key]accounting.Coverage
+		expected  []compensation.Pay
+		total     float64
+	}{
+		{
+			name:      "no coverage",
+			coverages: map[accounting.Key]accounting.Coverage{},
+			expected:  []compensation.Pay{},
+			total:     0,
+		},
+		{
+			name: "holders are sorted and their tracks are summed",
+			coverages: map[accounting.Key]accounting.Coverage{
+				{Track: model.DefaultTrack, Holder: "@b:example.com"}: {
+					HolidayHours: 30 * time.Minute, RestDays: 1,
+				},
+				{Track: model.DefaultTrack, Holder: "@a:example.com"}: {
+					BusinessHours: 8 * time.Hour, OffHours: time.Hour, WorkingDays: 1,
+				},
+				{Track: "database", Holder: "@a:example.com"}: {
+					OffHours: 2 * time.Hour, WeekendHours: 10 * time.Minute, WorkingDays: 1, RestDays: 1,
+				},
+			},
+			expected: []compensation.Pay{
+				{
+					Holder: "@a:example.com",
+					Coverage: accounting.Coverage{
+						BusinessHours: 8 * time.Hour, OffHours: 3 * time.Hour, WeekendHours: 10 * time.Minute,
+						WorkingDays: 2, RestDays: 1,
+					},
+					Amount: 18.5,
+				},
+				{
+					Holder:   "@b:example.com",
+					Coverage: accounting.Coverage{HolidayHours: 30 * time.Minute, RestDays: 1},
+					Amount:   2,
+				},
+			},
+			total: 20.5,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := compensation.Calculate(test.coverages, rates)
+
+			if got.Rates != rates {
+				t.Errorf("expected %+v rates, got %+v", rates, got.Rates)
+			}
+
+			if len(got.Pays) != len(test.expected) {
+				t.Fatalf("expected %d pays, got %+v", len(test.expected), got.Pays)
+			}
+
+			for i, expected := range test.expected {
+				if got.Pays[i] != expected {
+					t.Errorf("expected %+v at %d, got %+v", expected, i, got.Pays[i])
+				}
+			}
+
+			if got.Total() != test.total {
+				t.Errorf("expected %v total, got %v", test.total, got.Total())
+			}
+		})
+	}
+}
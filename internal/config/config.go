@@ -23,8 +23,11 @@ const (
 
 type (
 	Config struct {
-		Matrix   Matrix   `mapstructure:"matrix"`
-		Database Database `mapstructure:"database"`
+		Matrix       Matrix       `mapstructure:"matrix"`
+		Database     Database     `mapstructure:"database"`
+		Scheduler    Scheduler    `mapstructure:"scheduler"`
+		Compensation Compensation `mapstructure:"compensation"`
+		HTTP         HTTP         `mapstructure:"http"`
 	}
 
 	Matrix struct {
@@ -48,6 +51,26 @@ type (
 		MaxOpenConnections int           `mapstructure:"max_open_connections"`
 		MaxIdleConnections int           `mapstructure:"max_idle_connections"`
 	}
+
+	Scheduler struct {
+		Interval time.Duration `mapstructure:"interval" validate:"gt=0"`
+	}
+
+	// HTTP is the server of the calendar feeds.
+	HTTP struct {
+		Address string `mapstructure:"address"`
+		// PublicURL is the URL that the users reach the server at, which the links of the feeds start with.
+		PublicURL string `mapstructure:"public_url" validate:"url"`
+	}
+
+	// Compensation is the default hourly rates of the on call time, which the rooms can override.
+	Compensation struct {
+		Currency      string  `mapstructure:"currency"`
+		BusinessHours float64 `mapstructure:"business_hours" validate:"gte=0"`
+		OffHours      float64 `mapstructure:"off_hours" validate:"gte=0"`
+		Weekend       float64 `mapstructure:"weekend" validate:"gte=0"`
+		Holiday       float64 `mapstructure:"holiday" validate:"gte=0"`
+	}
 )
 
 // Validate validates Config struct.
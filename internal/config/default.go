@@ -20,4 +20,18 @@ database:
   connection_lifetime: "10m"
   max_open_connections: 10
   max_idle_connections: 5
+
+scheduler:
+  interval: "1m"
+
+compensation:
+  currency: ""
+  business_hours: 0
+  off_hours: 1
+  weekend: 1.5
+  holiday: 2
+
+http:
+  address: ":8080"
+  public_url: "http://localhost:8080"
 `
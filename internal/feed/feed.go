@@ -0,0 +1,141 @@
+// Package feed serves the shifts of the rooms and the users as read-only iCalendar feeds, so they can be subscribed to
+// in calendar apps. Each feed is reached by the secret token in its URL.
+package feed
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/ical"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+const (
+	// Path is the path of the feeds, which is followed by the token and the extension of a feed.
+	Path      = "/calendar/"
+	Extension = ".ics"
+
+	// history is how long ago the oldest shifts of a feed have ended.
+	history = 90 * 24 * time.Hour
+
+	readHeaderTimeout = 10 * time.Second
+)
+
+// URL returns the URL of the feed with the token on the public URL of the server.
+func URL(publicURL, token string) string {
+	return strings.TrimSuffix(publicURL, "/") + Path + token + Extension
+}
+
+// NewServer returns an HTTP server of the feeds on the address.
+func NewServer(address string, tokenRepo model.CalendarTokenRepo, shiftRepo model.ShiftRepo) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(Path, &Handler{TokenRepo: tokenRepo, ShiftRepo: shiftRepo})
+
+	return &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+}
+
+// Handler serves the feed of the token in the path of the requests.
+type Handler struct {
+	TokenRepo model.CalendarTokenRepo
+	ShiftRepo model.ShiftRepo
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	token := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, Path), Extension)
+	if token == "" {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	calendarToken, err := h.TokenRepo.Find(token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.NotFound(w, r)
+
+		return
+	} else if err != nil {
+		logrus.WithField("error", err.Error()).Error("error getting calendar token")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+		return
+	}
+
+	now := time.Now()
+	from := now.Add(-history)
+
+	shifts, err := h.ShiftRepo.Feed(calendarToken.RoomID, model.ShiftFilter{Holder: calendarToken.UserID, From: &from})
+	if err != nil {
+		logrus.WithField("error", err.Error()).Error("error getting shifts of calendar feed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+		return
+	}
+
+	name := "On call shifts of " + calendarToken.RoomID
+	if calendarToken.UserID != "" {
+		name = "On call shifts of " + calendarToken.UserID
+	}
+
+	w.Header().Set("Content-Type", ical.ContentType)
+
+	if err := ical.Write(w, name, Events(shifts, now), now); err != nil {
+		logrus.WithField("error", err.Error()).Error("error writing calendar feed")
+	}
+}
+
+// Events returns the events of the shifts. The shifts that are not ended yet end at their planned end time, or now if
+// they have none.
+func Events(shifts []model.Shift, now time.Time) []ical.Event {
+	res := make([]ical.Event, 0, len(shifts))
+
+	for _, shift := range shifts {
+		end := now
+
+		switch {
+		case shift.EndTime != nil:
+			end = *shift.EndTime
+		case shift.PlannedEndTime != nil:
+			end = *shift.PlannedEndTime
+		case shift.StartTime.After(now):
+			end = shift.StartTime
+		}
+
+		summary := "On call"
+		if shift.Track != model.DefaultTrack {
+			summary = fmt.Sprintf("On call (%s)", shift.Track)
+		}
+
+		holders := make([]string, 0, len(shift.Holders))
+		for _, holder := range shift.Holders {
+			holders = append(holders, fmt.Sprintf("%s (%s)", holder.Holder, holder.Tier))
+		}
+
+		res = append(res, ical.Event{
+			UID:     fmt.Sprintf("shift-%d@matrix-on-call-bot", shift.ID),
+			Summary: summary,
+			Description: fmt.Sprintf("Holders: %s\nRoom: %s\nShift id: %d",
+				strings.Join(holders, ", "), shift.RoomID, shift.ID),
+			Start:  shift.StartTime,
+			End:    end,
+			AllDay: false,
+		})
+	}
+
+	return res
+}
@@ -0,0 +1,363 @@
+// Package ical parses and writes the events of iCalendar (.ics) files, as described in RFC 5545. Only the properties
+// that the bot uses are supported and recurrence rules are not expanded.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	dateLayout        = "20060102"
+	dateTimeLayout    = "20060102T150405"
+	utcDateTimeLayout = "20060102T150405Z"
+
+	hoursOfDay  = 24
+	decimalBase = 10
+
+	// ContentType is the media type of the calendars.
+	ContentType = "text/calendar; charset=utf-8"
+
+	productID = "-//snapp-incubator//matrix-on-call-bot//EN"
+	// lineOctets is the maximum length of a content line, longer lines are folded.
+	lineOctets = 75
+)
+
+var (
+	ErrNoCalendar   = errors.New("no calendar found")
+	ErrInvalidEvent = errors.New("invalid event")
+)
+
+// Event is an event of a calendar. End is exclusive and the times of an all day event are at the start of its days.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+}
+
+// Days returns the start of every day that the event takes some time of.
+func (e Event) Days() []time.Time {
+	res := make([]time.Time, 0)
+
+	day := time.Date(e.Start.Year(), e.Start.Month(), e.Start.Day(), 0, 0, 0, 0, e.Start.Location())
+
+	for ; day.Before(e.End) || day.Equal(e.Start); day = day.AddDate(0, 0, 1) {
+		res = append(res, day)
+	}
+
+	return res
+}
+
+// property is a content line of a calendar, like: DTSTART;TZID=Asia/Tehran:20221017T090000.
+type property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Parse returns the events of the calendar. The dates and the times without a time zone are in the given location.
+//
+//nolint:cyclop
+func Parse(r io.Reader, loc *time.Location) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Event, 0)
+	found := false
+
+	var (
+		event    *Event
+		hasEnd   bool
+		endTime  time.Time
+		duration *time.Duration
+	)
+
+	for _, line := range lines {
+		prop := parseProperty(line)
+
+		switch {
+		case prop.Name == "BEGIN" && strings.EqualFold(prop.Value, "VCALENDAR"):
+			found = true
+		case prop.Name == "BEGIN" && strings.EqualFold(prop.Value, "VEVENT"):
+			event = &Event{}
+			hasEnd = false
+			duration = nil
+		case prop.Name == "END" && strings.EqualFold(prop.Value, "VEVENT") && event != nil:
+			if event.Start.IsZero() {
+				return nil, errors.Wrapf(ErrInvalidEvent, "event %q has no start", event.Summary)
+			}
+
+			event.End = endTime
+
+			// The duration may come before the start, so the end is resolved when the whole event is read.
+			if duration != nil {
+				event.End = event.Start.Add(*duration)
+			}
+
+			if !hasEnd {
+				// An event without an end takes its start day if it's an all day event, or its start time otherwise.
+				event.End = event.Start
+				if event.AllDay {
+					event.End = event.Start.AddDate(0, 0, 1)
+				}
+			}
+
+			res = append(res, *event)
+			event = nil
+		case event == nil:
+			continue
+		case prop.Name == "UID":
+			event.UID = prop.Value
+		case prop.Name == "SUMMARY":
+			event.Summary = unescape(prop.Value)
+		case prop.Name == "DESCRIPTION":
+			event.Description = unescape(prop.Value)
+		case prop.Name == "DTSTART":
+			if event.Start, event.AllDay, err = parseTime(prop, loc); err != nil {
+				return nil, err
+			}
+		case prop.Name == "DTEND":
+			if endTime, _, err = parseTime(prop, loc); err != nil {
+				return nil, err
+			}
+
+			hasEnd = true
+		case prop.Name == "DURATION":
+			value, err := parseDuration(prop.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			duration = &value
+			hasEnd = true
+		}
+	}
+
+	if !found {
+		return nil, ErrNoCalendar
+	}
+
+	return res, nil
+}
+
+// unfold joins the content lines that are split into multiple lines.
+func unfold(r io.Reader) ([]string, error) {
+	res := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(res) > 0 {
+			res[len(res)-1] += line[1:]
+
+			continue
+		}
+
+		if line != "" {
+			res = append(res, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "error reading calendar")
+	}
+
+	return res, nil
+}
+
+func parseProperty(line string) property {
+	prop := property{Name: "", Params: make(map[string]string), Value: ""}
+
+	// The value starts after the first colon that is not quoted in the parameters.
+	quoted := false
+	split := len(line)
+
+	for i, char := range line {
+		if char == '"' {
+			quoted = !quoted
+		} else if char == ':' && !quoted {
+			split = i
+
+			break
+		}
+	}
+
+	if split < len(line) {
+		prop.Value = line[split+1:]
+	}
+
+	params := strings.Split(line[:split], ";")
+	prop.Name = strings.ToUpper(params[0])
+
+	for _, param := range params[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			prop.Params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+
+	return prop
+}
+
+// parseTime parses the date or date-time value of the property. It also reports whether the value is a date.
+func parseTime(prop property, loc *time.Location) (time.Time, bool, error) {
+	value := prop.Value
+
+	if prop.Params["VALUE"] == "DATE" || len(value) == len(dateLayout) {
+		at, err := time.ParseInLocation(dateLayout, value, loc)
+
+		return at, true, errors.Wrapf(err, "invalid date of %s", prop.Name)
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		at, err := time.Parse(utcDateTimeLayout, value)
+
+		return at, false, errors.Wrapf(err, "invalid time of %s", prop.Name)
+	}
+
+	if tzid, ok := prop.Params["TZID"]; ok {
+		// Time zones that are not known by their IANA name are taken as the given location.
+		if zone, err := time.LoadLocation(tzid); err == nil {
+			loc = zone
+		}
+	}
+
+	at, err := time.ParseInLocation(dateTimeLayout, value, loc)
+
+	return at, false, errors.Wrapf(err, "invalid time of %s", prop.Name)
+}
+
+// parseDuration parses a duration value like P1D or PT1H30M. Months and years are not valid in a duration.
+func parseDuration(value string) (time.Duration, error) {
+	sign := time.Duration(1)
+
+	if strings.HasPrefix(value, "-") {
+		sign = -1
+	}
+
+	value = strings.TrimLeft(value, "+-")
+	if !strings.HasPrefix(value, "P") {
+		return 0, errors.Errorf("invalid duration %s", value)
+	}
+
+	var (
+		res    time.Duration
+		number int
+		inTime bool
+	)
+
+	units := map[bool]map[rune]time.Duration{
+		false: {'W': 7 * hoursOfDay * time.Hour, 'D': hoursOfDay * time.Hour},
+		true:  {'H': time.Hour, 'M': time.Minute, 'S': time.Second},
+	}
+
+	for _, char := range value[1:] {
+		switch {
+		case char >= '0' && char <= '9':
+			number = number*decimalBase + int(char-'0')
+		case char == 'T':
+			inTime = true
+		default:
+			unit, ok := units[inTime][char]
+			if !ok {
+				return 0, errors.Errorf("invalid duration %s", value)
+			}
+
+			res += time.Duration(number) * unit
+			number = 0
+		}
+	}
+
+	return sign * res, nil
+}
+
+func unescape(value string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
+
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+// Write writes the events as a calendar with the given name. The times are written in UTC and now is the time stamp of
+// the events.
+func Write(w io.Writer, name string, events []Event, now time.Time) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + productID,
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + escape(name),
+	}
+
+	for _, event := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+event.UID,
+			"DTSTAMP:"+now.UTC().Format(utcDateTimeLayout),
+			formatTime("DTSTART", event.Start, event.AllDay),
+			formatTime("DTEND", event.End, event.AllDay),
+			"SUMMARY:"+escape(event.Summary),
+		)
+
+		if event.Description != "" {
+			lines = append(lines, "DESCRIPTION:"+escape(event.Description))
+		}
+
+		lines = append(lines, "END:VEVENT")
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	writer := bufio.NewWriter(w)
+
+	for _, line := range lines {
+		if _, err := writer.WriteString(fold(line) + "\r\n"); err != nil {
+			return errors.Wrap(err, "error writing calendar")
+		}
+	}
+
+	return errors.Wrap(writer.Flush(), "error writing calendar")
+}
+
+func formatTime(name string, at time.Time, allDay bool) string {
+	if allDay {
+		return name + ";VALUE=DATE:" + at.Format(dateLayout)
+	}
+
+	return name + ":" + at.UTC().Format(utcDateTimeLayout)
+}
+
+// fold splits the long content line into lines of at most lineOctets octets without splitting its characters. The
+// next lines start with a space.
+func fold(line string) string {
+	var res strings.Builder
+
+	length := 0
+
+	for _, char := range line {
+		size := len(string(char))
+
+		if length+size > lineOctets {
+			res.WriteString("\r\n ")
+
+			length = 1
+		}
+
+		res.WriteRune(char)
+
+		length += size
+	}
+
+	return res.String()
+}
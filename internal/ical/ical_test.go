@@ -0,0 +1,228 @@
+package ical_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata" // Imported for the time zones of the tests
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/ical"
+)
+
+func tehran(t *testing.T) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation("Asia/Tehran")
+	if err != nil {
+		t.Fatalf("error loading time zone: %s", err)
+	}
+
+	return loc
+}
+
+func calendar(lines ...string) string {
+	return strings.Join(append(append([]string{"BEGIN:VCALENDAR"}, lines...), "END:VCALENDAR"), "\r\n")
+}
+
+func equalEvents(a, b ical.Event) bool {
+	return a.UID == b.UID && a.Summary == b.Summary && a.Description == b.Description && a.AllDay == b.AllDay &&
+		a.Start.Equal(b.Start) && a.End.Equal(b.End)
+}
+
+// nolint: funlen
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	loc := tehran(t)
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("error loading time zone: %s", err)
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected []ical.Event
+		err      error
+	}{
+		{
+			name: "time zone",
+			content: calendar(
+				"BEGIN:VEVENT", "UID:1", "SUMMARY:On call",
+				"DTSTART;TZID=Europe/Berlin:20221030T010000", "DTEND;TZID=Europe/Berlin:20221030T040000",
+				"END:VEVENT",
+			),
+			expected: []ical.Event{{
+				UID: "1", Summary: "On call",
+				Start: time.Date(2022, 10, 30, 1, 0, 0, 0, berlin), End: time.Date(2022, 10, 30, 4, 0, 0, 0, berlin),
+			}},
+		},
+		{
+			name: "utc and local times",
+			content: calendar(
+				"BEGIN:VEVENT", "DTSTART:20221017T053000Z", "DTEND:20221017T120000", "END:VEVENT",
+			),
+			expected: []ical.Event{{
+				Start: time.Date(2022, 10, 17, 5, 30, 0, 0, time.UTC), End: time.Date(2022, 10, 17, 12, 0, 0, 0, loc),
+			}},
+		},
+		{
+			name: "all day events",
+			content: calendar(
+				"BEGIN:VEVENT", "SUMMARY:Holiday", "DTSTART;VALUE=DATE:20221017", "DTEND;VALUE=DATE:20221019",
+				"END:VEVENT",
+				"BEGIN:VEVENT", "SUMMARY:No end", "DTSTART;VALUE=DATE:20221020", "END:VEVENT",
+			),
+			expected: []ical.Event{
+				{
+					Summary: "Holiday", AllDay: true,
+					Start: time.Date(2022, 10, 17, 0, 0, 0, 0, loc), End: time.Date(2022, 10, 19, 0, 0, 0, 0, loc),
+				},
+				{
+					Summary: "No end", AllDay: true,
+					Start: time.Date(2022, 10, 20, 0, 0, 0, 0, loc), End: time.Date(2022, 10, 21, 0, 0, 0, 0, loc),
+				},
+			},
+		},
+		{
+			name: "duration",
+			content: calendar(
+				"BEGIN:VEVENT", "DTSTART:20221017T090000Z", "DURATION:P1DT2H30M", "END:VEVENT",
+			),
+			expected: []ical.Event{{
+				Start: time.Date(2022, 10, 17, 9, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 18, 11, 30, 0, 0, time.UTC),
+			}},
+		},
+		{
+			name: "duration before the start",
+			content: calendar(
+				"BEGIN:VEVENT", "DURATION:PT8H", "DTSTART;TZID=Europe/Berlin:20221030T000000", "END:VEVENT",
+			),
+			expected: []ical.Event{{
+				Start: time.Date(2022, 10, 30, 0, 0, 0, 0, berlin), End: time.Date(2022, 10, 30, 7, 0, 0, 0, berlin),
+			}},
+		},
+		{
+			name: "folded and escaped text",
+			content: calendar(
+				"BEGIN:VEVENT", "DTSTART:20221017T090000Z",
+				`SUMMARY:On call\, pri`, " mary", `DESCRIPTION:First line\nsecond\; line`,
+				"END:VEVENT",
+			),
+			expected: []ical.Event{{
+				Summary: "On call, primary", Description: "First line\nsecond; line",
+				Start: time.Date(2022, 10, 17, 9, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 17, 9, 0, 0, 0, time.UTC),
+			}},
+		},
+		{
+			name:    "no calendar",
+			content: "BEGIN:VEVENT\r\nDTSTART:20221017T090000Z\r\nEND:VEVENT",
+			err:     ical.ErrNoCalendar,
+		},
+		{
+			name:    "event without a start",
+			content: calendar("BEGIN:VEVENT", "SUMMARY:On call", "END:VEVENT"),
+			err:     ical.ErrInvalidEvent,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ical.Parse(strings.NewReader(test.content), loc)
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Errorf("expected %s, got %v", test.err, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if len(got) != len(test.expected) {
+				t.Fatalf("expected %d events, got %+v", len(test.expected), got)
+			}
+
+			for i, expected := range test.expected {
+				if !equalEvents(got[i], expected) {
+					t.Errorf("expected %+v at %d, got %+v", expected, i, got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	loc := tehran(t)
+	now := time.Date(2022, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		events []ical.Event
+	}{
+		{
+			name:   "no events",
+			events: []ical.Event{},
+		},
+		{
+			name: "events",
+			events: []ical.Event{
+				{
+					UID: "shift-1@example.com", Summary: "On call; primary, database",
+					Description: "Handed off by @a:example.com\nEnded by @b:example.com",
+					Start:       time.Date(2022, 10, 17, 9, 0, 0, 0, loc), End: time.Date(2022, 10, 18, 9, 0, 0, 0, loc),
+				},
+				{
+					UID: "holiday-1@example.com", Summary: strings.Repeat("Holidäy ", 20), AllDay: true,
+					Start: time.Date(2022, 10, 20, 0, 0, 0, 0, loc), End: time.Date(2022, 10, 21, 0, 0, 0, 0, loc),
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			if err := ical.Write(&buf, "On call, room", test.events, now); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+				if len(line) > 75 {
+					t.Errorf("expected lines of at most 75 octets, got %d: %s", len(line), line)
+				}
+			}
+
+			got, err := ical.Parse(&buf, loc)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if len(got) != len(test.events) {
+				t.Fatalf("expected %d events, got %+v", len(test.events), got)
+			}
+
+			for i, expected := range test.events {
+				if !equalEvents(got[i], expected) {
+					t.Errorf("expected %+v at %d, got %+v", expected, i, got[i])
+				}
+			}
+		})
+	}
+}
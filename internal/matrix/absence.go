@@ -0,0 +1,250 @@
+package matrix
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matrix-org/gomatrix"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+const (
+	listAway   = "list"
+	deleteAway = "delete"
+
+	minCreateAwayLength int = 3
+	minDeleteAwayLength int = 3
+)
+
+func (b *Bot) away(event *gomatrix.Event, parts []string) error {
+	if len(parts) < minAwayLength {
+		return b.invalidAway(event)
+	}
+
+	switch parts[1] {
+	case listAway:
+		return b.listAway(event)
+	case deleteAway:
+		return b.deleteAway(event, parts)
+	default:
+		return b.createAway(event, parts)
+	}
+}
+
+// createAway handles !away <from> <to> [reason].
+func (b *Bot) createAway(event *gomatrix.Event, parts []string) error {
+	if len(parts) < minCreateAwayLength {
+		return b.invalidAway(event)
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
+	from, err := time.ParseInLocation(dateTimeLayout, parts[1], loc)
+	if err != nil {
+		return b.invalidAwayWithError(event, err)
+	}
+
+	to, err := time.ParseInLocation(dateTimeLayout, parts[2], loc)
+	if err != nil {
+		return b.invalidAwayWithError(event, err)
+	}
+
+	if !from.Before(to) {
+		return b.invalidAwayWithError(event, errors.New("from must be before to"))
+	}
+
+	absence := model.Absence{
+		RoomID:    event.RoomID,
+		Holder:    event.Sender,
+		StartTime: from,
+		EndTime:   to,
+		Reason:    strings.Join(parts[3:], " "),
+	}
+
+	if err := b.absenceRepo.Create(&absence); err != nil {
+		return errors.Wrap(err, "error saving absence")
+	}
+
+	holder, err := b.mention(event.Sender)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf(AwayCreated, holder, formatTime(from, loc), formatTime(to, loc),
+		absence.ID)
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending away created message")
+	}
+
+	return nil
+}
+
+// listAway lists the current and upcoming absences of the members of the room. The absences apply to all the rooms, so
+// the ones that are recorded in other rooms are listed too.
+func (b *Bot) listAway(event *gomatrix.Event) error {
+	members, err := b.cli.JoinedMembers(event.RoomID)
+	if err != nil {
+		return errors.Wrap(err, "error getting room members")
+	}
+
+	holders := make([]string, 0, len(members.Joined))
+	for member := range members.Joined {
+		holders = append(holders, member)
+	}
+
+	absences, err := b.absenceRepo.Get(holders, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "error getting absences")
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
+	message := ""
+
+	for _, item := range absences {
+		holder, err := b.mention(item.Holder)
+		if err != nil {
+			return err
+		}
+
+		message += fmt.Sprintf(AwayItem, item.ID, holder, formatTime(item.StartTime, loc),
+			formatTime(item.EndTime, loc), absenceReason(item, event.RoomID))
+	}
+
+	message = fmt.Sprintf(AwayList, message)
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending absences list")
+	}
+
+	return nil
+}
+
+// deleteAway deletes an absence of the sender.
+func (b *Bot) deleteAway(event *gomatrix.Event, parts []string) error {
+	if len(parts) < minDeleteAwayLength {
+		return b.invalidAway(event)
+	}
+
+	absenceID, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return b.invalidAwayWithError(event, err)
+	}
+
+	message := fmt.Sprintf(AwayDeleted, absenceID)
+
+	if err := b.absenceRepo.Delete(event.Sender, absenceID); errors.Is(err, gorm.ErrRecordNotFound) {
+		message = fmt.Sprintf(AwayNotFound, absenceID)
+	} else if err != nil {
+		return errors.Wrap(err, "error deleting absence")
+	}
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending away deleted message")
+	}
+
+	return nil
+}
+
+// absentees returns the absences of the given people between from and to by their MXIDs.
+func (b *Bot) absentees(ids []string, from, to time.Time) (map[string]model.Absence, error) {
+	absences, err := b.absenceRepo.Overlapping(ids, from, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting absences")
+	}
+
+	res := make(map[string]model.Absence, len(absences))
+
+	for _, absence := range absences {
+		if _, ok := res[absence.Holder]; !ok {
+			res[absence.Holder] = absence
+		}
+	}
+
+	return res, nil
+}
+
+// warnAbsentees warns the sender of a command that some of the people they gave a shift are away between from and to.
+func (b *Bot) warnAbsentees(event *gomatrix.Event, ids []string, from, to time.Time) error {
+	absentees, err := b.absentees(ids, from, to)
+	if err != nil {
+		return err
+	}
+
+	if len(absentees) == 0 {
+		return nil
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
+	sender, err := b.mention(event.Sender)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		absence, ok := absentees[id]
+		if !ok {
+			continue
+		}
+
+		holder, err := b.mention(id)
+		if err != nil {
+			return err
+		}
+
+		message := fmt.Sprintf(AwayWarning, sender, holder, formatTime(absence.StartTime, loc),
+			formatTime(absence.EndTime, loc), absenceReason(absence, event.RoomID))
+
+		if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+			return errors.Wrap(err, "error sending away warning")
+		}
+	}
+
+	return nil
+}
+
+// absenceReason returns the reason of the absence to be shown in the room. The reasons of the absences that are
+// recorded in other rooms are hidden, as the other rooms may be private.
+func absenceReason(absence model.Absence, roomID string) string {
+	if absence.RoomID != roomID {
+		return HiddenAwayReason
+	}
+
+	if absence.Reason == "" {
+		return "-"
+	}
+
+	return absence.Reason
+}
+
+func (b *Bot) invalidAway(event *gomatrix.Event) error {
+	if _, err := b.cli.SendText(event.RoomID, InvalidAwayCommand); err != nil {
+		return errors.Wrap(err, "error sending invalid away command message")
+	}
+
+	return nil
+}
+
+func (b *Bot) invalidAwayWithError(event *gomatrix.Event, err error) error {
+	if _, err := b.cli.SendText(event.RoomID, fmt.Sprintf(InvalidAwayCommandWithError, err.Error())); err != nil {
+		return errors.Wrap(err, "error sending invalid away command message")
+	}
+
+	return nil
+}
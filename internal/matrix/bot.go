@@ -1,6 +1,7 @@
 package matrix
 
 import (
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -8,12 +9,15 @@ import (
 	"github.com/matrix-org/gomatrix"
 	"github.com/sirupsen/logrus"
 
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/compensation"
 	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/schedule"
 )
 
 const (
 	RoomMemberEvent  = "m.room.member"
 	RoomMessageEvent = "m.room.message"
+	ReactionEvent    = "m.reaction"
 	ResyncWaitTime   = 300 * time.Millisecond
 )
 
@@ -26,15 +30,37 @@ type Bot struct {
 	userID      string
 	autoJoin    bool
 
-	roomRepo     model.RoomRepo
-	shiftRepo    model.ShiftRepo
-	followUpRepo model.FollowUpRepo
+	roomRepo       model.RoomRepo
+	shiftRepo      model.ShiftRepo
+	followUpRepo   model.FollowUpRepo
+	rotationRepo   model.RotationRepo
+	overrideRepo   model.OverrideRepo
+	swapRepo       model.SwapRepo
+	noteRepo       model.NoteRepo
+	shiftEditRepo  model.ShiftEditRepo
+	reminderRepo   model.ReminderRepo
+	absenceRepo    model.AbsenceRepo
+	preferenceRepo model.PreferenceRepo
+	holidayRepo    model.HolidayRepo
+	// calendarTokenRepo is the repo of the tokens of the calendar feeds, which are served on the public URL.
+	calendarTokenRepo model.CalendarTokenRepo
+	publicURL         string
+
+	calculator *compensation.Calculator
+	importer   *schedule.Importer
+	// previewedImports is the replied files of the rooms that are previewed and can be confirmed.
+	previewedImports map[string]bool
+	importsLock      sync.Mutex
 
 	stopSignal chan struct{}
 }
 
 func New(url, userID, token, displayName string,
 	roomRepo model.RoomRepo, shiftRepo model.ShiftRepo, followUpRepo model.FollowUpRepo,
+	rotationRepo model.RotationRepo, overrideRepo model.OverrideRepo, swapRepo model.SwapRepo, noteRepo model.NoteRepo,
+	shiftEditRepo model.ShiftEditRepo, reminderRepo model.ReminderRepo,
+	absenceRepo model.AbsenceRepo, preferenceRepo model.PreferenceRepo, holidayRepo model.HolidayRepo,
+	calendarTokenRepo model.CalendarTokenRepo, rates compensation.Rates, publicURL string,
 ) (*Bot, error) {
 	cli, err := gomatrix.NewClient(url, userID, token)
 	if err != nil {
@@ -42,14 +68,34 @@ func New(url, userID, token, displayName string,
 	}
 
 	return &Bot{
-		cli:          cli,
-		displayName:  displayName,
-		userID:       userID,
-		autoJoin:     true,
-		roomRepo:     roomRepo,
-		shiftRepo:    shiftRepo,
-		followUpRepo: followUpRepo,
-		stopSignal:   make(chan struct{}, 1),
+		cli:               cli,
+		displayName:       displayName,
+		userID:            userID,
+		autoJoin:          true,
+		roomRepo:          roomRepo,
+		shiftRepo:         shiftRepo,
+		followUpRepo:      followUpRepo,
+		rotationRepo:      rotationRepo,
+		overrideRepo:      overrideRepo,
+		swapRepo:          swapRepo,
+		noteRepo:          noteRepo,
+		shiftEditRepo:     shiftEditRepo,
+		reminderRepo:      reminderRepo,
+		absenceRepo:       absenceRepo,
+		preferenceRepo:    preferenceRepo,
+		holidayRepo:       holidayRepo,
+		calendarTokenRepo: calendarTokenRepo,
+		publicURL:         publicURL,
+		calculator: &compensation.Calculator{
+			RoomRepo:     roomRepo,
+			ShiftRepo:    shiftRepo,
+			OverrideRepo: overrideRepo,
+			HolidayRepo:  holidayRepo,
+			Rates:        rates,
+		},
+		importer:         &schedule.Importer{ShiftRepo: shiftRepo},
+		previewedImports: make(map[string]bool),
+		stopSignal:       make(chan struct{}),
 	}, nil
 }
 
@@ -99,6 +145,19 @@ func (b *Bot) RegisterListeners() error {
 		}
 	})
 
+	syncer.OnEventType(ReactionEvent, func(event *gomatrix.Event) {
+		if event.Sender == b.userID {
+			return
+		}
+
+		if err := b.HandleReaction(event); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": err.Error(),
+				"event": event,
+			}).Error("error handling reaction")
+		}
+	})
+
 	return nil
 }
 
@@ -123,5 +182,5 @@ func (b *Bot) Run() {
 
 func (b *Bot) Stop() {
 	b.cli.StopSync()
-	b.stopSignal <- struct{}{}
+	close(b.stopSignal)
 }
@@ -0,0 +1,125 @@
+package matrix
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/matrix-org/gomatrix"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/feed"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+const (
+	linkCalendar  = "link"
+	resetCalendar = "reset"
+	// mineCalendar is the option of the calendar commands for the feed of the sender in all the rooms.
+	mineCalendar = "mine"
+)
+
+// calendarFeed sends the link of the calendar feed of the room or the sender with !calendar link [mine], or replaces it
+// with !calendar reset [mine]. The link of the sender is sent in a direct message.
+func (b *Bot) calendarFeed(event *gomatrix.Event, parts []string) error {
+	if len(parts) < minCalendarLength {
+		return b.invalidCalendarWithError(event, errors.New("missing subcommand"))
+	}
+
+	roomID, userID := event.RoomID, ""
+
+	mine := len(parts) > 2 && strings.EqualFold(parts[2], mineCalendar)
+	if mine {
+		roomID, userID = "", event.Sender
+	}
+
+	var (
+		token  *model.CalendarToken
+		err    error
+		prefix string
+	)
+
+	switch strings.ToLower(parts[1]) {
+	case linkCalendar:
+		token, err = b.calendarTokenRepo.Get(roomID, userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			token, err = b.newCalendarToken(roomID, userID)
+		} else if err != nil {
+			err = errors.Wrap(err, "error getting calendar token")
+		}
+	case resetCalendar:
+		token, err = b.newCalendarToken(roomID, userID)
+		prefix = CalendarLinkReset
+	default:
+		return b.invalidCalendarWithError(event, errors.Errorf("unknown subcommand %s", parts[1]))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	link := feed.URL(b.publicURL, token.Token)
+	message := prefix + fmt.Sprintf(CalendarLink, link, Calendar, resetCalendar)
+
+	if mine {
+		return b.sendPersonalCalendar(event, prefix, link)
+	}
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending calendar link")
+	}
+
+	return nil
+}
+
+// sendPersonalCalendar sends the link of the feed of the sender in a direct message, so it is never posted in a room
+// that others can read.
+func (b *Bot) sendPersonalCalendar(event *gomatrix.Event, prefix, link string) error {
+	mention, err := b.mention(event.Sender)
+	if err != nil {
+		return err
+	}
+
+	roomID, err := b.directRoom(event.Sender)
+	if err != nil {
+		return err
+	}
+
+	message := prefix + fmt.Sprintf(CalendarLinkOfUser, mention, link, Calendar, resetCalendar, mineCalendar)
+
+	if _, err := b.cli.SendFormattedText(roomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending calendar link")
+	}
+
+	if roomID == event.RoomID {
+		return nil
+	}
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", fmt.Sprintf(CalendarLinkSent, mention)); err != nil {
+		return errors.Wrap(err, "error sending calendar link sent message")
+	}
+
+	return nil
+}
+
+// newCalendarToken saves a new token for the feed of the room or the user, which replaces the previous one.
+func (b *Bot) newCalendarToken(roomID, userID string) (*model.CalendarToken, error) {
+	token, err := model.NewCalendarToken(roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.calendarTokenRepo.Save(token); err != nil {
+		return nil, errors.Wrap(err, "error saving calendar token")
+	}
+
+	return token, nil
+}
+
+func (b *Bot) invalidCalendarWithError(event *gomatrix.Event, err error) error {
+	if _, err := b.cli.SendText(event.RoomID, fmt.Sprintf(InvalidCalendarCommandWithError, err.Error())); err != nil {
+		return errors.Wrap(err, "error sending invalid calendar command message")
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package matrix
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matrix-org/gomatrix"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const confirmCancel = "confirm"
+
+// cancelShift cancels a shift that was created by mistake, together with its follow ups. The first call only shows
+// what is going to be cancelled and the shift is cancelled when the command is repeated with confirm.
+func (b *Bot) cancelShift(event *gomatrix.Event, parts []string) error {
+	if len(parts) < minCancelShiftLength {
+		return b.invalidCancelShift(event)
+	}
+
+	shiftID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return b.invalidCancelShift(event)
+	}
+
+	shift, err := b.shiftRepo.Find(event.RoomID, shiftID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if _, err := b.cli.SendFormattedText(event.RoomID, "", fmt.Sprintf(ShiftNotFound, shiftID)); err != nil {
+			return errors.Wrap(err, "error sending shift not found message")
+		}
+
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "error getting shift")
+	}
+
+	var message string
+
+	if len(parts) > minCancelShiftLength && strings.EqualFold(parts[2], confirmCancel) {
+		if err := b.shiftRepo.Cancel(shift, event.Sender, time.Now()); err != nil {
+			return errors.Wrap(err, "error cancelling shift")
+		}
+
+		message = fmt.Sprintf(ShiftCancelled, shiftID)
+	} else {
+		followUps, err := b.followUpRepo.Get(shiftID)
+		if err != nil {
+			return errors.Wrap(err, "error getting follow ups")
+		}
+
+		loc, err := b.location(event.RoomID, event.Sender)
+		if err != nil {
+			return err
+		}
+
+		holders, err := b.holdersText(shift.Holders)
+		if err != nil {
+			return err
+		}
+
+		message = fmt.Sprintf(ShiftCancelConfirmation, shiftID, holders, formatTime(shift.StartTime, loc),
+			len(followUps), CancelShift, shiftID, confirmCancel)
+	}
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending shift cancelled message")
+	}
+
+	return nil
+}
+
+func (b *Bot) invalidCancelShift(event *gomatrix.Event) error {
+	if _, err := b.cli.SendText(event.RoomID, InvalidCancelShiftCommand); err != nil {
+		return errors.Wrap(err, "error sending invalid cancel shift command message")
+	}
+
+	return nil
+}
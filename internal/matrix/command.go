@@ -3,15 +3,19 @@ package matrix
 import (
 	"bytes"
 	"fmt"
-	"math"
+	"html"
+	"html/template"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/matrix-org/gomatrix"
 	"github.com/pkg/errors"
+	"gorm.io/gorm"
 
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/accounting"
 	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
 )
 
@@ -21,25 +25,84 @@ const (
 	incoming string = "incoming"
 	outgoing string = "outgoing"
 
-	ListShift        Head = "!listshifts" // !listshifts
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02T15:04"
+	clockLayout    = "15:04"
+
+	trackOption  = "track"
+	defaultTrack = "default"
+
+	pageOption     = "page"
+	activeFilter   = "active"
+	fromFilter     = "from"
+	toFilter       = "to"
+	shiftsPageSize = 10
+
+	ListShift        Head = "!listshifts" // !listshifts [active] [mentioned holder] [from <date>] [to <date>] [page=<n>]
 	minCommandLength int  = 1
 
-	CreateShift          Head = "!startshift" // !startshift <comma separated oncall names>
+	CreateShift          Head = "!startshift" // !startshift [track=<name>] <comma separated oncall names>
 	minCreateShiftLength int  = 1
 
+	ScheduleShift          Head = "!scheduleshift" // !scheduleshift [track=<name>] <mentioned oncalls> <start> <end>
+	minScheduleShiftLength int  = 3
+
+	ImportShifts Head = "!importshifts" // !importshifts [confirm] (as a reply to a .csv or .ics file)
+
 	EndShift          Head = "!endshift" // !endshift <shift id>
 	minEndShiftLength int  = 2
 
+	Handoff Head = "!handoff" // !handoff [track=<name>] <mentioned next oncalls>
+
+	// !editshift <shift id> [start=<ts>] [end=<ts>|none] [holders=<mentioned holders>]
+	EditShift          Head = "!editshift"
+	minEditShiftLength int  = 3
+
+	ShiftHistory          Head = "!shifthistory" // !shifthistory <shift id>
+	minShiftHistoryLength int  = 2
+
+	CancelShift          Head = "!cancelshift" // !cancelshift <shift id> [confirm]
+	minCancelShiftLength int  = 2
+
 	CreateFollowUp          Head = "!followup" // !followup <category: incoming|outgoing> <initiator> <description>
 	minCreateFollowUpLength int  = 4
 
 	ListFollowUp Head = "!listfollowups" // !listfollowups
 
+	CreateNote          Head = "!note" // !note <text>
+	minCreateNoteLength int  = 2
+
+	Override          Head = "!override" // !override <mentioned coverer> [mentioned covered] <from> <to>
+	minOverrideLength int  = 4
+
 	ResolveFollowUp          Head = "!resolvefollowup" // !resolvefollowup <id>
 	minResolveFollowUpLength int  = 2
 
 	Report Head = "!report" // !report
 
+	OnCall Head = "!oncall" // !oncall [room id or alias]
+
+	Suggest Head = "!suggest" // !suggest [track=<name>] [days=<n>] [mentioned candidates]
+
+	Rotation          Head = "!rotation" // !rotation <create|list|delete> ...
+	minRotationLength int  = 2
+
+	Swap          Head = "!swap" // !swap <request|accept|decline|list> ...
+	minSwapLength int  = 2
+
+	Away          Head = "!away" // !away <from> <to> [reason] | !away list | !away delete <id>
+	minAwayLength int  = 2
+
+	Settings Head = "!settings" // !settings
+	Set      Head = "!set"      // !set <setting> <value>
+	TimeZone Head = "!timezone" // !timezone [zone|local]
+
+	Holidays          Head = "!holidays" // !holidays [list|import]
+	minHolidaysLength int  = 2
+
+	Calendar          Head = "!calendar" // !calendar <link|reset> [mine]
+	minCalendarLength int  = 2
+
 	Help = "!help" // !help
 )
 
@@ -61,31 +124,67 @@ func (b *Bot) Handle(event *gomatrix.Event) error {
 		return ErrInvalidBody
 	}
 
-	parts := strings.Split(raw, " ")
+	parts := strings.Split(stripReply(raw), " ")
 
 	if len(parts) < minCommandLength {
 		return ErrInvalidCommand
 	}
 
-	if parts[0][0] != '!' {
+	if parts[0] == "" || parts[0][0] != '!' {
 		return nil
 	}
 
 	switch Head(parts[0]) {
 	case CreateShift:
 		return b.createShift(event, parts)
+	case ScheduleShift:
+		return b.scheduleShift(event, parts)
+	case ImportShifts:
+		return b.importShifts(event, parts)
 	case EndShift:
 		return b.endShift(event, parts)
+	case Handoff:
+		return b.handoff(event, parts)
+	case EditShift:
+		return b.editShift(event, parts)
+	case ShiftHistory:
+		return b.shiftHistory(event, parts)
+	case CancelShift:
+		return b.cancelShift(event, parts)
 	case ListShift:
-		return b.listShifts(event)
+		return b.listShifts(event, parts)
+	case Override:
+		return b.override(event, parts)
 	case CreateFollowUp:
 		return b.createFollowUp(event, parts)
+	case CreateNote:
+		return b.createNote(event, parts)
 	case ListFollowUp:
-		return b.listFollowUps(event)
+		return b.listFollowUps(event, parts)
 	case ResolveFollowUp:
 		return b.resolveFollowUp(event, parts)
 	case Report:
 		return b.report(event, parts)
+	case OnCall:
+		return b.onCallCommand(event, parts)
+	case Suggest:
+		return b.suggest(event, parts)
+	case Rotation:
+		return b.rotation(event, parts)
+	case Swap:
+		return b.swap(event, parts)
+	case Away:
+		return b.away(event, parts)
+	case Settings:
+		return b.settings(event)
+	case Set:
+		return b.set(event, parts)
+	case TimeZone:
+		return b.timeZone(event, parts)
+	case Holidays:
+		return b.holidays(event, parts)
+	case Calendar:
+		return b.calendarFeed(event, parts)
 	case Help:
 		return b.help(event)
 	default:
@@ -95,6 +194,8 @@ func (b *Bot) Handle(event *gomatrix.Event) error {
 
 //nolint:funlen,cyclop
 func (b *Bot) createShift(event *gomatrix.Event, parts []string) error {
+	track, _, parts := option(parts, trackOption)
+
 	if len(parts) < minCreateShiftLength {
 		return ErrInvalidCommand
 	}
@@ -106,32 +207,31 @@ func (b *Bot) createShift(event *gomatrix.Event, parts []string) error {
 		}
 	}
 
-	mxids := make([]string, 0)
-	names := make([]string, 0)
-	mentions := make([]string, 0)
-
-	if len(parts) == 1 {
-		mxids = append(mxids, event.Sender)
+	var holders []Mention
 
+	if len(parts) == 1 || (len(parts) == 2 && model.IsTier(parts[1])) {
 		displayName, err := b.cli.GetDisplayName(event.Sender)
 		if err != nil {
 			return errors.Wrap(err, "error getting the display name of the event sender")
 		}
 
-		mentions = append(mentions, b.mentionedText(event.Sender, displayName.DisplayName))
-		names = append(names, displayName.DisplayName)
+		tier := model.TierPrimary
+		if len(parts) == 2 {
+			tier = strings.ToLower(parts[1])
+		}
+
+		holders = append(holders, Mention{
+			ID:   event.Sender,
+			Name: displayName.DisplayName,
+			Link: b.mentionedText(event.Sender, displayName.DisplayName),
+			Tier: tier,
+		})
 	} else {
-		formattedBodyStr, ok := formattedBody.(string)
-		if !ok {
+		if _, ok := formattedBody.(string); !ok {
 			return errors.Wrap(ErrInvalidType, "error getting the display name of the event sender")
 		}
 
-		items := Regexp.FindAllStringSubmatch(formattedBodyStr, -1)
-		for _, parts := range items {
-			mentions = append(mentions, parts[0])
-			mxids = append(mxids, parts[1])
-			names = append(names, parts[2])
-		}
+		holders = mentionsOf(event)
 	}
 
 	active, err := b.shiftRepo.Active(event.RoomID)
@@ -141,7 +241,7 @@ func (b *Bot) createShift(event *gomatrix.Event, parts []string) error {
 
 	//nolint:godox
 	// TODO: Check weather this condition should be checked or not
-	if len(active) > 0 {
+	if len(model.InTrack(active, track)) > 0 {
 		if _, err := b.cli.SendText(event.RoomID, ActiveShiftOngoing); err != nil {
 			return errors.Wrap(err, "error sending active shift message")
 		}
@@ -150,22 +250,33 @@ func (b *Bot) createShift(event *gomatrix.Event, parts []string) error {
 	}
 
 	now := time.Now()
+	names := make([]string, 0, len(holders))
+	mentions := make([]string, 0, len(holders))
 
-	for _, mxid := range mxids {
-		//nolint:godox
-		// TODO: Create a bulk Create method
-		if err := b.shiftRepo.Create(&model.Shift{
-			RoomID:    event.RoomID,
-			Sender:    event.Sender,
-			Holders:   mxid,
-			StartTime: now,
-			EndTime:   nil,
-		}); err != nil {
-			return errors.Wrap(err, "error saving shift")
-		}
+	for _, holder := range holders {
+		names = append(names, fmt.Sprintf(TieredHolder, holder.Name, holder.Tier))
+		mentions = append(mentions, fmt.Sprintf(TieredHolder, holder.Link, holder.Tier))
 	}
 
-	formattedTime := now.Local().Format(time.RFC850)
+	shift := model.Shift{
+		RoomID:    event.RoomID,
+		Sender:    event.Sender,
+		Holders:   shiftHolders(holders),
+		Track:     track,
+		StartTime: now,
+		EndTime:   nil,
+	}
+
+	if err := b.shiftRepo.Create(&shift); err != nil {
+		return errors.Wrap(err, "error saving shift")
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
+	formattedTime := formatTime(now, loc)
 
 	_, err = b.cli.SendFormattedText(event.RoomID, fmt.Sprintf(ShiftStarted, strings.Join(names, " "), formattedTime),
 		fmt.Sprintf(ShiftStarted, strings.Join(mentions, " "), formattedTime))
@@ -173,6 +284,130 @@ func (b *Bot) createShift(event *gomatrix.Event, parts []string) error {
 		return errors.Wrap(err, "error sending shift created message")
 	}
 
+	return b.warnAbsentees(event, shift.HolderIDs(), now, now)
+}
+
+// scheduleShift creates shifts for the mentioned people (or the sender) that start and end in the future. They are
+// started and ended by the scheduler.
+//
+//nolint:funlen,cyclop
+func (b *Bot) scheduleShift(event *gomatrix.Event, parts []string) error {
+	track, _, parts := option(parts, trackOption)
+
+	if len(parts) < minScheduleShiftLength {
+		if _, err := b.cli.SendText(event.RoomID, InvalidScheduleShiftCommand); err != nil {
+			return errors.Wrap(err, "error sending invalid schedule shift command message")
+		}
+
+		return nil
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
+	start, err := time.ParseInLocation(dateTimeLayout, parts[len(parts)-2], loc)
+	if err != nil {
+		return b.invalidScheduleShiftWithError(event, err)
+	}
+
+	end, err := time.ParseInLocation(dateTimeLayout, parts[len(parts)-1], loc)
+	if err != nil {
+		return b.invalidScheduleShiftWithError(event, err)
+	}
+
+	if !start.Before(end) {
+		return b.invalidScheduleShiftWithError(event, errors.New("start must be before end"))
+	}
+
+	if !start.After(time.Now()) {
+		return b.invalidScheduleShiftWithError(event,
+			errors.Errorf("start must be in the future, use %s instead", CreateShift))
+	}
+
+	holders := mentionsOf(event)
+	if len(holders) == 0 {
+		mention, err := b.mention(event.Sender)
+		if err != nil {
+			return err
+		}
+
+		holders = append(holders, Mention{ID: event.Sender, Link: mention, Tier: model.TierPrimary})
+	}
+
+	overlapping, err := b.shiftRepo.Overlapping(event.RoomID, start, end)
+	if err != nil {
+		return errors.Wrap(err, "error getting overlapping shifts")
+	}
+
+	conflicts := make([]string, 0)
+
+	for _, shift := range model.InTrack(overlapping, track) {
+		// Shifts without an end are ended by a person and are not taken as a conflict.
+		if shift.EndTime != nil || shift.PlannedEndTime != nil {
+			conflicts = append(conflicts, strconv.Itoa(shift.ID))
+		}
+	}
+
+	if len(conflicts) > 0 {
+		message := fmt.Sprintf(ScheduledShiftConflict, strings.Join(conflicts, ", "))
+
+		if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+			return errors.Wrap(err, "error sending scheduled shift conflict message")
+		}
+
+		return nil
+	}
+
+	mentions := make([]string, 0, len(holders))
+
+	for _, holder := range holders {
+		mentions = append(mentions, fmt.Sprintf(TieredHolder, holder.Link, holder.Tier))
+	}
+
+	shift := model.Shift{
+		RoomID:         event.RoomID,
+		Sender:         event.Sender,
+		Holders:        shiftHolders(holders),
+		Track:          track,
+		StartTime:      start,
+		EndTime:        nil,
+		PlannedEndTime: &end,
+		Pending:        true,
+	}
+
+	if err := b.shiftRepo.Create(&shift); err != nil {
+		return errors.Wrap(err, "error saving shift")
+	}
+
+	message := fmt.Sprintf(ShiftScheduled, strings.Join(mentions, " "),
+		formatTime(start, loc), formatTime(end, loc), shift.ID)
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending shift scheduled message")
+	}
+
+	return b.warnAbsentees(event, shift.HolderIDs(), start, end)
+}
+
+func (b *Bot) invalidListShiftsWithError(event *gomatrix.Event, err error) error {
+	message := fmt.Sprintf(InvalidListShiftsCommandWithError, err.Error())
+
+	if _, err := b.cli.SendText(event.RoomID, message); err != nil {
+		return errors.Wrap(err, "error sending invalid list shifts command message")
+	}
+
+	return nil
+}
+
+func (b *Bot) invalidScheduleShiftWithError(event *gomatrix.Event, err error) error {
+	message := fmt.Sprintf(InvalidScheduleShiftCommandWithError, err.Error())
+
+	if _, err := b.cli.SendText(event.RoomID, message); err != nil {
+		return errors.Wrap(err, "error sending invalid schedule shift command message")
+	}
+
 	return nil
 }
 
@@ -186,16 +421,51 @@ func (b *Bot) endShift(event *gomatrix.Event, parts []string) error {
 		return errors.Wrap(err, "invalid shift id")
 	}
 
-	now := time.Now()
+	shift, err := b.shiftRepo.Find(event.RoomID, shiftID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if _, err := b.cli.SendFormattedText(event.RoomID, "", fmt.Sprintf(ShiftNotFound, shiftID)); err != nil {
+			return errors.Wrap(err, "error sending shift not found message")
+		}
 
-	if err := b.shiftRepo.Update(&model.Shift{
-		ID:      shiftID,
-		EndTime: &now,
-	}); err != nil {
-		return errors.Wrap(err, "error updating shift")
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "error getting shift")
 	}
 
-	_, err = b.cli.SendFormattedText(event.RoomID, fmt.Sprintf(ShiftEnd, shiftID), fmt.Sprintf(ShiftEndFormatted, shiftID))
+	if shift.EndTime == nil && shift.StartTime.After(time.Now()) {
+		if _, err := b.cli.SendFormattedText(event.RoomID, "", fmt.Sprintf(ShiftNotStarted, shiftID, CancelShift,
+			shiftID)); err != nil {
+			return errors.Wrap(err, "error sending shift not started message")
+		}
+
+		return nil
+	}
+
+	if shift.EndTime == nil {
+		now := time.Now()
+		shift.EndTime = &now
+
+		if err := b.shiftRepo.Update(&model.Shift{
+			ID:      shiftID,
+			EndTime: &now,
+			EndedBy: event.Sender,
+		}); err != nil {
+			return errors.Wrap(err, "error updating shift")
+		}
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
+	summary, err := b.shiftSummary(shift, loc)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.cli.SendFormattedText(event.RoomID, fmt.Sprintf(ShiftEnd, shiftID),
+		fmt.Sprintf(ShiftEndFormatted, shiftID)+summary)
 	if err != nil {
 		return errors.Wrap(err, "error sending shift end message")
 	}
@@ -203,33 +473,206 @@ func (b *Bot) endShift(event *gomatrix.Event, parts []string) error {
 	return nil
 }
 
-func (b *Bot) listShifts(event *gomatrix.Event) error {
-	list, err := b.shiftRepo.Get(event.RoomID)
+// handoff ends the active shift of the room and starts a new one for the mentioned people (or the sender if no one is
+// mentioned) at once. Unresolved follow ups are carried over to the new shift.
+func (b *Bot) handoff(event *gomatrix.Event, parts []string) error {
+	track, _, _ := option(parts, trackOption)
+
+	holders := mentionsOf(event)
+	if len(holders) == 0 {
+		mention, err := b.mention(event.Sender)
+		if err != nil {
+			return err
+		}
+
+		holders = append(holders, Mention{ID: event.Sender, Link: mention, Tier: model.TierPrimary})
+	}
+
+	now := time.Now()
+	links := make([]string, 0, len(holders))
+
+	for _, holder := range holders {
+		links = append(links, fmt.Sprintf(TieredHolder, holder.Link, holder.Tier))
+	}
+
+	shift := model.Shift{
+		RoomID:    event.RoomID,
+		Sender:    event.Sender,
+		Holders:   shiftHolders(holders),
+		Track:     track,
+		StartTime: now,
+		EndTime:   nil,
+	}
+
+	if err := b.shiftRepo.Handoff(&shift); err != nil {
+		return errors.Wrap(err, "error handing off shift")
+	}
+
+	followUps, err := b.followUpRepo.Get(shift.ID)
+	if err != nil {
+		return errors.Wrap(err, "error getting follow ups")
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf(ShiftHandedOff, strings.Join(links, " "), formatTime(now, loc),
+		shift.ID, len(followUps), ListFollowUp)
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending shift handed off message")
+	}
+
+	return b.warnAbsentees(event, shift.HolderIDs(), now, now)
+}
+
+// listShifts lists a page of the shifts of the room, newest first. The shifts can be filtered by being active, a
+// mentioned holder, a track and a date range.
+//
+//nolint:funlen,cyclop,gocognit
+func (b *Bot) listShifts(event *gomatrix.Event, parts []string) error {
+	pageValue, _, parts := option(parts, pageOption)
+	track, filterTrack, parts := option(parts, trackOption)
+
+	var filter model.ShiftFilter
+
+	if filterTrack {
+		filter.Track = &track
+	}
+
+	page := 1
+
+	if pageValue != "" {
+		var err error
+
+		if page, err = strconv.Atoi(pageValue); err != nil || page < 1 {
+			return b.invalidListShiftsWithError(event, errors.Errorf("invalid page %s", pageValue))
+		}
+	}
+
+	// hint is the command of the next page, which is built from the filter, since the body of the event only has the
+	// display names of the mentioned people.
+	hint := []string{string(ListShift)}
+
+	if people := mentionsOf(event); len(people) > 0 {
+		filter.Holder = people[0].ID
+		hint = append(hint, people[0].Link)
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
+	for i := 1; i < len(parts); i++ {
+		switch strings.ToLower(parts[i]) {
+		case activeFilter:
+			filter.Active = true
+			hint = append(hint, activeFilter)
+		case fromFilter, toFilter:
+			if i+1 >= len(parts) {
+				return b.invalidListShiftsWithError(event, errors.Errorf("%s needs a date", parts[i]))
+			}
+
+			day, err := time.ParseInLocation(dateLayout, parts[i+1], loc)
+			if err != nil {
+				return b.invalidListShiftsWithError(event, err)
+			}
+
+			if strings.EqualFold(parts[i], fromFilter) {
+				filter.From = &day
+			} else {
+				// The to day is included in the range.
+				day = day.AddDate(0, 0, 1)
+				filter.To = &day
+			}
+
+			hint = append(hint, strings.ToLower(parts[i]), parts[i+1])
+			i++
+		}
+	}
+
+	if filterTrack {
+		hint = append(hint, trackOption+"="+trackName(track))
+	}
+
+	offset := (page - 1) * shiftsPageSize
+
+	list, total, err := b.shiftRepo.List(event.RoomID, filter, offset, shiftsPageSize)
 	if err != nil {
 		return errors.Wrap(err, "error getting shifts")
 	}
 
+	if len(list) == 0 {
+		if _, err := b.cli.SendText(event.RoomID, fmt.Sprintf(NoShiftFound, total)); err != nil {
+			return errors.Wrap(err, "error sending no shift found message")
+		}
+
+		return nil
+	}
+
+	now := time.Now()
+
+	overrides, err := b.overrideRepo.Active(event.RoomID, now)
+	if err != nil {
+		return errors.Wrap(err, "error getting active overrides")
+	}
+
 	message := ""
 
 	for _, item := range list {
 		end := "-"
 		emoji := "🟢"
 
-		if item.EndTime != nil {
-			end = item.EndTime.Local().Format(time.RFC850)
+		switch {
+		case item.EndTime != nil:
+			end = formatTime(*item.EndTime, loc)
 			emoji = "⚪️"
+		case item.PlannedEndTime != nil:
+			end = fmt.Sprintf(PlannedEnd, formatTime(*item.PlannedEndTime, loc))
 		}
 
-		displayName, err := b.cli.GetDisplayName(item.Holders)
-		if err != nil {
-			return errors.Wrap(err, "error getting the display name of the event sender")
+		if item.Upcoming(now) {
+			emoji = "🕒"
+		}
+
+		holders := make([]string, 0, len(item.Holders))
+
+		for _, holder := range item.Holders {
+			mention, err := b.mention(holder.Holder)
+			if err != nil {
+				return err
+			}
+
+			text := fmt.Sprintf(TieredHolder, mention, holder.Tier)
+
+			for _, override := range overrides {
+				// Only the shifts in progress are covered now, like the reports credit the override.
+				if item.EndTime == nil && !item.Upcoming(now) && override.Covers(holder.Holder, now, now) {
+					coverer, err := b.mention(override.Holder)
+					if err != nil {
+						return err
+					}
+
+					text += fmt.Sprintf(ShiftCoveredBy, coverer, formatTime(override.EndTime, loc))
+				}
+			}
+
+			holders = append(holders, text)
 		}
 
 		message += fmt.Sprintf(ShiftItem, emoji,
-			item.StartTime.Local().Format(time.RFC850), end, b.mentionedText(item.Holders, displayName.DisplayName), item.ID)
+			formatTime(item.StartTime, loc), end, strings.Join(holders, ", "), trackName(item.Track), item.ID)
 	}
 
-	message = fmt.Sprintf(ShiftList, message)
+	message = fmt.Sprintf(ShiftList, offset+1, message) +
+		fmt.Sprintf(ShiftListPage, offset+1, offset+len(list), total)
+
+	if int64(offset+len(list)) < total {
+		message += fmt.Sprintf(ShiftListNextPage, strings.Join(hint, " "), pageOption, page+1)
+	}
 
 	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
 		return errors.Wrap(err, "error sending shifts list")
@@ -238,25 +681,142 @@ func (b *Bot) listShifts(event *gomatrix.Event) error {
 	return nil
 }
 
-func (b *Bot) createFollowUp(event *gomatrix.Event, parts []string) error {
-	if len(parts) < minCreateFollowUpLength {
-		return ErrInvalidCommand
+// override lets the mentioned person cover the shift of the sender (or the second mentioned person) between from and
+// to.
+//
+//nolint:funlen
+func (b *Bot) override(event *gomatrix.Event, parts []string) error {
+	people := mentionsOf(event)
+
+	if len(parts) < minOverrideLength || len(people) == 0 {
+		if _, err := b.cli.SendText(event.RoomID, InvalidOverrideCommand); err != nil {
+			return errors.Wrap(err, "error sending invalid override command message")
+		}
+
+		return nil
 	}
 
-	active, err := b.shiftRepo.Active(event.RoomID)
+	holder := people[0]
+	covered := Mention{ID: event.Sender, Link: ""}
+
+	if len(people) > 1 {
+		covered = people[1]
+	} else {
+		mention, err := b.mention(event.Sender)
+		if err != nil {
+			return err
+		}
+
+		covered.Link = mention
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
 	if err != nil {
-		return errors.Wrap(err, "error getting active shifts")
+		return err
 	}
 
-	if len(active) < 1 {
-		if _, err := b.cli.SendText(event.RoomID, NoActiveShiftOngoing); err != nil {
-			return errors.Wrap(err, "error sending no active shift message")
+	from, err := time.ParseInLocation(dateTimeLayout, parts[len(parts)-2], loc)
+	if err != nil {
+		return b.invalidOverrideWithError(event, err)
+	}
+
+	to, err := time.ParseInLocation(dateTimeLayout, parts[len(parts)-1], loc)
+	if err != nil {
+		return b.invalidOverrideWithError(event, err)
+	}
+
+	if !from.Before(to) {
+		return b.invalidOverrideWithError(event, errors.New("from must be before to"))
+	}
+
+	shifts, err := b.shiftRepo.Overlapping(event.RoomID, from, to)
+	if err != nil {
+		return errors.Wrap(err, "error getting overlapping shifts")
+	}
+
+	var shift *model.Shift
+
+	for i := range shifts {
+		if shifts[i].HeldBy(covered.ID) {
+			shift = &shifts[i]
+
+			break
+		}
+	}
+
+	formattedFrom := formatTime(from, loc)
+	formattedTo := formatTime(to, loc)
+
+	if shift == nil {
+		message := fmt.Sprintf(NoShiftToOverride, covered.Link, formattedFrom, formattedTo)
+
+		if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+			return errors.Wrap(err, "error sending no shift to override message")
+		}
+
+		return nil
+	}
+
+	absentees, err := b.absentees([]string{holder.ID}, from, to)
+	if err != nil {
+		return err
+	}
+
+	if absence, away := absentees[holder.ID]; away {
+		message := fmt.Sprintf(OverrideCovererAway, holder.Link, formatTime(absence.StartTime, loc),
+			formatTime(absence.EndTime, loc), absenceReason(absence, event.RoomID))
+
+		if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+			return errors.Wrap(err, "error sending override coverer away message")
 		}
 
 		return nil
 	}
 
-	shiftID := active[0].ID
+	override := model.Override{
+		ShiftID:   shift.ID,
+		RoomID:    event.RoomID,
+		Sender:    event.Sender,
+		Holder:    holder.ID,
+		Covered:   covered.ID,
+		StartTime: from,
+		EndTime:   to,
+	}
+
+	if err := b.overrideRepo.Create(&override); err != nil {
+		return errors.Wrap(err, "error saving override")
+	}
+
+	message := fmt.Sprintf(OverrideCreated, holder.Link, covered.Link, formattedFrom, formattedTo, shift.ID, override.ID)
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending override created message")
+	}
+
+	return nil
+}
+
+func (b *Bot) invalidOverrideWithError(event *gomatrix.Event, err error) error {
+	if _, err := b.cli.SendText(event.RoomID, fmt.Sprintf(InvalidOverrideCommandWithError, err.Error())); err != nil {
+		return errors.Wrap(err, "error sending invalid override command message")
+	}
+
+	return nil
+}
+
+func (b *Bot) createFollowUp(event *gomatrix.Event, parts []string) error {
+	track, found, parts := option(parts, trackOption)
+
+	if len(parts) < minCreateFollowUpLength {
+		return ErrInvalidCommand
+	}
+
+	shift, ok, err := b.activeShift(event, track, found)
+	if err != nil || !ok {
+		return err
+	}
+
+	shiftID := shift.ID
 	sender := event.Sender
 	category := b.followUpCategory(parts[1])
 	initiator := parts[2]
@@ -290,12 +850,61 @@ func (b *Bot) createFollowUp(event *gomatrix.Event, parts []string) error {
 	return nil
 }
 
-func (b *Bot) listFollowUps(event *gomatrix.Event) error {
+// activeShift returns the active shift of the room, or of the track if it is given, that follow ups and notes are left
+// on. The sender is asked for the track if the shifts of several tracks are active. It reports whether there is such a
+// shift.
+func (b *Bot) activeShift(event *gomatrix.Event, track string, filterTrack bool) (*model.Shift, bool, error) {
+	active, err := b.shiftRepo.Active(event.RoomID)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "error getting active shifts")
+	}
+
+	if filterTrack {
+		active = model.InTrack(active, track)
+	}
+
+	if len(active) < 1 {
+		if _, err := b.cli.SendText(event.RoomID, NoActiveShiftOngoing); err != nil {
+			return nil, false, errors.Wrap(err, "error sending no active shift message")
+		}
+
+		return nil, false, nil
+	}
+
+	tracks := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, shift := range active {
+		if !seen[shift.Track] {
+			seen[shift.Track] = true
+			tracks = append(tracks, trackName(shift.Track))
+		}
+	}
+
+	if len(tracks) > 1 {
+		message := fmt.Sprintf(AmbiguousTrack, strings.Join(tracks, ", "), trackOption)
+
+		if _, err := b.cli.SendText(event.RoomID, message); err != nil {
+			return nil, false, errors.Wrap(err, "error sending ambiguous track message")
+		}
+
+		return nil, false, nil
+	}
+
+	return &active[0], true, nil
+}
+
+// listFollowUps lists the follow ups of the active shifts of the room, or only the given track.
+func (b *Bot) listFollowUps(event *gomatrix.Event, parts []string) error {
 	active, err := b.shiftRepo.Active(event.RoomID)
 	if err != nil {
 		return errors.Wrap(err, "error getting active shifts")
 	}
 
+	if track, found, _ := option(parts, trackOption); found {
+		active = model.InTrack(active, track)
+	}
+
 	if len(active) < 1 {
 		if _, err := b.cli.SendText(event.RoomID, NoActiveShiftOngoing); err != nil {
 			return errors.Wrap(err, "error sending no active shift message")
@@ -304,11 +913,20 @@ func (b *Bot) listFollowUps(event *gomatrix.Event) error {
 		return nil
 	}
 
-	shiftID := active[0].ID
+	var items []model.FollowUp
+
+	for _, shift := range active {
+		shiftItems, err := b.followUpRepo.Get(shift.ID)
+		if err != nil {
+			return errors.Wrap(err, "error getting follow ups")
+		}
+
+		items = append(items, shiftItems...)
+	}
 
-	items, err := b.followUpRepo.Get(shiftID)
+	loc, err := b.location(event.RoomID, event.Sender)
 	if err != nil {
-		return errors.Wrap(err, "error getting follow ups")
+		return err
 	}
 
 	message := ""
@@ -322,7 +940,7 @@ func (b *Bot) listFollowUps(event *gomatrix.Event) error {
 
 		message += fmt.Sprintf(FollowUpItem,
 			emoji, item.ID, item.Category,
-			item.Initiator, item.Description, item.CreatedAt.Local().Format(time.RFC850))
+			item.Initiator, item.Description, formatTime(item.CreatedAt, loc))
 	}
 
 	message = fmt.Sprintf(FollowUpList, message)
@@ -334,6 +952,36 @@ func (b *Bot) listFollowUps(event *gomatrix.Event) error {
 	return nil
 }
 
+// createNote leaves a note on the active shift of the room (or the given track) for the handover summary.
+func (b *Bot) createNote(event *gomatrix.Event, parts []string) error {
+	track, found, parts := option(parts, trackOption)
+
+	if len(parts) < minCreateNoteLength {
+		return ErrInvalidCommand
+	}
+
+	shift, ok, err := b.activeShift(event, track, found)
+	if err != nil || !ok {
+		return err
+	}
+
+	note := model.Note{
+		ShiftID: shift.ID,
+		Sender:  event.Sender,
+		Body:    strings.Join(parts[1:], " "),
+	}
+
+	if err := b.noteRepo.Create(&note); err != nil {
+		return errors.Wrap(err, "error saving note")
+	}
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", fmt.Sprintf(NoteCreated, note.ShiftID)); err != nil {
+		return errors.Wrap(err, "error sending note created message")
+	}
+
+	return nil
+}
+
 func (b *Bot) resolveFollowUp(event *gomatrix.Event, parts []string) error {
 	if len(parts) < minResolveFollowUpLength {
 		return ErrInvalidCommand
@@ -357,8 +1005,16 @@ func (b *Bot) resolveFollowUp(event *gomatrix.Event, parts []string) error {
 	return nil
 }
 
+// help lists the commands together with the rules of the room.
 func (b *Bot) help(event *gomatrix.Event) error {
-	_, err := b.cli.SendFormattedText(event.RoomID, "", HelpList)
+	room, err := b.room(event.RoomID)
+	if err != nil {
+		return err
+	}
+
+	message := HelpList + fmt.Sprintf(HelpWeekend, weekendText(room), Set)
+
+	_, err = b.cli.SendFormattedText(event.RoomID, "", message)
 	if err != nil {
 		return errors.Wrap(err, "error sending help response")
 	}
@@ -378,37 +1034,191 @@ func (b *Bot) followUpCategory(in string) string {
 }
 
 func (b *Bot) mentionedText(id, name string) string {
-	return `<a href="https://matrix.to/#/` + id + `">` + name + `</a>`
+	return `<a href="https://matrix.to/#/` + id + `">` + html.EscapeString(name) + `</a>`
+}
+
+// option extracts the value of a key=value option from the parts of a command. It also reports whether the option is
+// given and returns the rest of the parts.
+func option(parts []string, key string) (string, bool, []string) {
+	value := ""
+	found := false
+	rest := make([]string, 0, len(parts))
+
+	for _, part := range parts {
+		if k, v, ok := strings.Cut(part, "="); ok && strings.EqualFold(k, key) {
+			value = v
+			found = true
+
+			continue
+		}
+
+		rest = append(rest, part)
+	}
+
+	if key == trackOption && strings.EqualFold(value, defaultTrack) {
+		value = model.DefaultTrack
+	}
+
+	return value, found, rest
+}
+
+func trackName(track string) string {
+	if track == model.DefaultTrack {
+		return defaultTrack
+	}
+
+	return track
 }
 
+// mention returns the mentioned text of the given MXID using its display name.
+func (b *Bot) mention(id string) (string, error) {
+	displayName, err := b.cli.GetDisplayName(id)
+	if err != nil {
+		return "", errors.Wrap(err, "error getting the display name")
+	}
+
+	return b.mentionedText(id, displayName.DisplayName), nil
+}
+
+// holdersText returns the mentioned texts of the holders of a shift together with their tiers.
+func (b *Bot) holdersText(holders []model.ShiftHolder) (string, error) {
+	res := make([]string, 0, len(holders))
+
+	for _, holder := range holders {
+		mention, err := b.mention(holder.Holder)
+		if err != nil {
+			return "", err
+		}
+
+		res = append(res, fmt.Sprintf(TieredHolder, mention, holder.Tier))
+	}
+
+	return strings.Join(res, ", "), nil
+}
+
+// shiftHolders returns the holders of a shift from the mentioned people.
+func shiftHolders(mentions []Mention) []model.ShiftHolder {
+	res := make([]model.ShiftHolder, 0, len(mentions))
+
+	for _, mention := range mentions {
+		res = append(res, model.ShiftHolder{Holder: mention.ID, Tier: mention.Tier})
+	}
+
+	return res
+}
+
+// Mention is a person mentioned in a message.
+type Mention struct {
+	ID   string
+	Name string
+	Link string
+	Tier string
+}
+
+// mentionsOf returns the people mentioned in the formatted body of the event in order of appearance.
+// A tier name written before mentions (like: "secondary: @a @b") sets the tier of the people after it, otherwise they
+// are primary.
+func mentionsOf(event *gomatrix.Event) []Mention {
+	formattedBody, ok := event.Content["formatted_body"].(string)
+	if !ok {
+		return nil
+	}
+
+	// The people of the replied message are not mentioned by the reply.
+	formattedBody = replyRegexp.ReplaceAllString(formattedBody, "")
+
+	items := Regexp.FindAllStringSubmatchIndex(formattedBody, -1)
+	res := make([]Mention, 0, len(items))
+	tier := model.TierPrimary
+	last := 0
+
+	for _, item := range items {
+		// Rooms are linked the same way as people, but their ids start with # or !.
+		if !strings.HasPrefix(formattedBody[item[2]:item[3]], "@") {
+			continue
+		}
+
+		for _, word := range strings.Fields(formattedBody[last:item[0]]) {
+			if word = strings.ToLower(strings.Trim(word, ":,")); model.IsTier(word) {
+				tier = word
+			}
+		}
+
+		res = append(res, Mention{
+			ID:   formattedBody[item[2]:item[3]],
+			Name: formattedBody[item[4]:item[5]],
+			Link: formattedBody[item[0]:item[1]],
+			Tier: tier,
+		})
+		last = item[1]
+	}
+
+	return res
+}
+
+// ShiftReportTemplate is the data of a report, which is rendered in the room or exported as a file.
 type ShiftReportTemplate struct {
-	Items []ShiftReportItemTemplate
-	From  string
-	To    string
+	Items        []ShiftReportItemTemplate
+	From         string
+	To           string
+	Weekend      string
+	Holidays     int
+	WorkingHours string
 }
 
 type ShiftReportItemTemplate struct {
-	HolderID   string
-	WorkingDay int
-	Holiday    int
+	// HolderID is the mention link of the holder and Holder is their MXID.
+	HolderID      template.HTML
+	Holder        string
+	DisplayName   string
+	Track         string
+	BusinessHours string
+	OffHours      string
+	RestDayHours  string
+	Total         string
+	WorkingDay    int
+	Holiday       int
+	// Coverage is the covered time of the item, which is exported in numbers.
+	Coverage accounting.Coverage
 }
 
 // nolint: funlen,gocognit, cyclop
 func (b *Bot) report(event *gomatrix.Event, parts []string) error {
+	track, filterTrack, parts := option(parts, trackOption)
+	format, export, parts := option(parts, formatOption)
+
+	if export && !exportFormats[strings.ToLower(format)] {
+		if _, err := b.cli.SendText(event.RoomID, fmt.Sprintf(InvalidReportCommandWithError,
+			fmt.Sprintf("unknown format %s, use %s or %s", format, csvFormat, jsonFormat))); err != nil {
+			return errors.Wrap(err, "error sending invalid report command message")
+		}
+
+		return nil
+	}
+
+	// !report pay takes the same time range as the report.
+	pay := len(parts) > 1 && strings.EqualFold(parts[1], payReport)
+	if pay {
+		parts = append([]string{parts[0]}, parts[2:]...)
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
 	// Handling custom time range report
 	//nolint: varnamelen
 	var from, to time.Time
 
-	var err error
-
 	switch {
 	case len(parts) == 1: // ["!report"]
-		to = time.Now()
-		from = time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, time.Local)
+		to = time.Now().In(loc)
+		from = time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, loc)
 	case len(parts) == 3 && strings.EqualFold(parts[1], "from"): // ["!report", "FROM", "2022-10-17"]
 		to = time.Now()
 
-		from, err = time.Parse(time.RFC3339, parts[2]+"T00:00:00Z")
+		from, err = time.ParseInLocation(dateLayout, parts[2], loc)
 		if err != nil {
 			if _, err = b.cli.SendText(event.RoomID, fmt.Sprintf(InvalidReportCommandWithError, err.Error())); err != nil {
 				return errors.Wrap(err, "error sending invalid report command message")
@@ -418,7 +1228,7 @@ func (b *Bot) report(event *gomatrix.Event, parts []string) error {
 		}
 	case len(parts) == 5 && strings.EqualFold(parts[1], "from") &&
 		strings.EqualFold(parts[3], "to"): // ["!report", "FROM", "2022-10-17", "TO", "2022-10-21"]
-		from, err = time.Parse(time.RFC3339, parts[2]+"T00:00:00Z")
+		from, err = time.ParseInLocation(dateLayout, parts[2], loc)
 		if err != nil {
 			if _, err = b.cli.SendText(event.RoomID, fmt.Sprintf(InvalidReportCommandWithError, err.Error())); err != nil {
 				return errors.Wrap(err, "error sending invalid report command message")
@@ -427,7 +1237,7 @@ func (b *Bot) report(event *gomatrix.Event, parts []string) error {
 			return nil
 		}
 
-		to, err = time.Parse(time.RFC3339, parts[4]+"T00:00:00Z")
+		to, err = time.ParseInLocation(dateLayout, parts[4], loc)
 		if err != nil {
 			if _, err = b.cli.SendText(event.RoomID, fmt.Sprintf(InvalidReportCommandWithError, err.Error())); err != nil {
 				return errors.Wrap(err, "error sending invalid report command message")
@@ -435,6 +1245,9 @@ func (b *Bot) report(event *gomatrix.Event, parts []string) error {
 
 			return nil
 		}
+
+		// The TO day is included, like the --to day of the pay CLI.
+		to = to.AddDate(0, 0, 1)
 	default: // Not valid format
 		if _, err = b.cli.SendText(event.RoomID, InvalidReportCommand); err != nil {
 			return errors.Wrap(err, "error sending invalid report command message")
@@ -443,62 +1256,84 @@ func (b *Bot) report(event *gomatrix.Event, parts []string) error {
 		return nil
 	}
 
+	if pay {
+		if filterTrack || export {
+			if _, err = b.cli.SendText(event.RoomID, fmt.Sprintf(InvalidReportCommandWithError,
+				"the pays cover all the tracks and can't be exported")); err != nil {
+				return errors.Wrap(err, "error sending invalid report command message")
+			}
+
+			return nil
+		}
+
+		return b.payReport(event, from, to, loc)
+	}
+
 	shifts, err := b.shiftRepo.Report(event.RoomID, from, to)
 	if err != nil {
 		return errors.Wrap(err, "error in getting shifts from the db")
 	}
 
-	shiftsRep := make([]ShiftReportItemTemplate, 0, len(shifts))
-	results := make(map[string]ShiftReportItemTemplate)
-
-	for _, shift := range shifts {
-		var temp ShiftReportItemTemplate
-
-		var ok bool
-
-		if temp, ok = results[shift.Holders]; !ok {
-			temp = ShiftReportItemTemplate{
-				HolderID:   shift.Holders,
-				WorkingDay: 0,
-				Holiday:    0,
-			}
-		}
+	overrides, err := b.overrideRepo.Report(event.RoomID, from, to)
+	if err != nil {
+		return errors.Wrap(err, "error in getting overrides from the db")
+	}
 
-		if shift.EndTime == nil {
-			shift.EndTime = &to
-		}
+	shifts = model.ApplyOverrides(shifts, overrides)
 
-		if from.After(shift.StartTime) {
-			shift.StartTime = from
-		}
+	if filterTrack {
+		shifts = model.InTrackReport(shifts, track)
+	}
 
-		if to.Before(*shift.EndTime) {
-			shift.EndTime = &to
-		}
+	shiftsRep := make([]ShiftReportItemTemplate, 0, len(shifts))
+	room, err := b.room(event.RoomID)
+	if err != nil {
+		return err
+	}
 
-		wd, hd := dateDiff(shift.StartTime, *shift.EndTime)
-		temp.WorkingDay += wd
-		temp.Holiday += hd
-		results[shift.Holders] = temp
+	calendar, err := b.calendar(room, from, to)
+	if err != nil {
+		return err
 	}
 
-	for _, result := range results {
-		displayName, err := b.cli.GetDisplayName(result.HolderID)
+	results := accounting.Tally(shifts, from, to, time.Now(), calendar)
+
+	for _, key := range sortedReport(results) {
+		displayName, err := b.cli.GetDisplayName(key.Holder)
 		if err != nil {
 			return errors.Wrap(err, "error getting the display name of the event sender")
 		}
 
+		coverage := results[key]
+
 		shiftsRep = append(shiftsRep, ShiftReportItemTemplate{
-			HolderID:   b.mentionedText(result.HolderID, displayName.DisplayName),
-			WorkingDay: result.WorkingDay,
-			Holiday:    result.Holiday,
+			HolderID:      template.HTML(b.mentionedText(key.Holder, displayName.DisplayName)), //nolint:gosec
+			Holder:        key.Holder,
+			DisplayName:   displayName.DisplayName,
+			Track:         trackName(key.Track),
+			BusinessHours: formatHours(coverage.BusinessHours),
+			OffHours:      formatHours(coverage.OffHours),
+			RestDayHours:  formatHours(coverage.RestDayHours()),
+			Total:         formatHours(coverage.Total()),
+			WorkingDay:    coverage.WorkingDays,
+			Holiday:       coverage.RestDays,
+			Coverage:      coverage,
 		})
 	}
 
 	tmp := ShiftReportTemplate{
-		Items: shiftsRep,
-		From:  from.Format(time.Stamp),
-		To:    to.Format(time.Stamp),
+		Items:        shiftsRep,
+		From:         formatTime(from, loc),
+		To:           formatTime(to, loc),
+		Weekend:      weekendText(room),
+		Holidays:     len(calendar.Holidays),
+		WorkingHours: room.WorkingHours,
+	}
+
+	if export {
+		name := fmt.Sprintf("report-%s-%s", from.In(loc).Format(dateLayout), to.In(loc).Format(dateLayout))
+
+		return b.exportReport(event.RoomID, strings.ToLower(format), name, tmp)
 	}
 
 	var buf bytes.Buffer
@@ -515,45 +1350,33 @@ func (b *Bot) report(event *gomatrix.Event, parts []string) error {
 	return nil
 }
 
-const (
-	weekDays = 7
-	dayHours = 24
-)
+// sortedReport returns the keys of the report grouped by track.
+func sortedReport(results map[accounting.Key]accounting.Coverage) []accounting.Key {
+	res := make([]accounting.Key, 0, len(results))
 
-func dateDiff(start, end time.Time) (int, int) {
-	var normalDays, holidays int
-	// List of days those are holidays during the week. For example Thursday and Friday is holiday in my country
-	weekHolidays := []time.Weekday{
-		time.Thursday,
-		time.Friday,
+	for key := range results {
+		res = append(res, key)
 	}
 
-	// Calculate number of days between start and end
-	diffDays := end.Sub(start).Hours()/dayHours + 1
-	// Calculate number of complete weeks between start and end
-	fullWeeks := math.Floor(diffDays / weekDays)
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Track != res[j].Track {
+			return res[i].Track < res[j].Track
+		}
 
-	// Each full weeks have the number holidays during it
-	fullWeeksHolidays := int(fullWeeks) * len(weekHolidays)
+		return res[i].Holder < res[j].Holder
+	})
 
-	if uint(diffDays)%weekDays == 0 {
-		holidays = fullWeeksHolidays
-	} else {
-		// nEnd is the end of the last full week
-		nEnd := start.Add(time.Duration(fullWeeks) * weekDays * dayHours * time.Hour)
-		counter := 0
-
-		// Calculate number of holidays during nEnd to end
-		for _, weekHolidayDay := range weekHolidays {
-			if nEnd.Weekday() <= weekHolidayDay && end.Weekday() >= weekHolidayDay {
-				counter++
-			}
-		}
+	return res
+}
 
-		holidays = fullWeeksHolidays + counter
-	}
+const (
+	weekDays = 7
+	dayHours = 24
+)
 
-	normalDays = int(diffDays) - holidays
+// formatHours formats a duration in hours and minutes.
+func formatHours(duration time.Duration) string {
+	duration = duration.Round(time.Minute)
 
-	return normalDays, holidays
+	return fmt.Sprintf("%dh %dm", duration/time.Hour, duration%time.Hour/time.Minute)
 }
@@ -0,0 +1,73 @@
+package matrix
+
+import (
+	"net/http"
+
+	"github.com/matrix-org/gomatrix"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DirectEvent is the account data event that lists the direct message rooms of a user by the other user.
+	DirectEvent = "m.direct"
+
+	directRoomPreset = "trusted_private_chat"
+)
+
+// directRoom returns the room of the direct messages of the bot with the user. A new room is created if they have no
+// direct message room yet.
+func (b *Bot) directRoom(userID string) (string, error) {
+	direct := make(map[string][]string)
+
+	var httpErr gomatrix.HTTPError
+
+	err := b.cli.MakeRequest(http.MethodGet, b.cli.BuildURL("user", b.userID, "account_data", DirectEvent), nil, &direct)
+	if errors.As(err, &httpErr) && httpErr.Code == http.StatusNotFound {
+		direct = make(map[string][]string)
+	} else if err != nil {
+		return "", errors.Wrap(err, "error getting direct rooms")
+	}
+
+	for _, roomID := range direct[userID] {
+		// The rooms that the user or the bot left are not direct rooms anymore.
+		if private, err := b.isDirectRoom(roomID, userID); err == nil && private {
+			return roomID, nil
+		}
+	}
+
+	room, err := b.cli.CreateRoom(&gomatrix.ReqCreateRoom{
+		Invite:   []string{userID},
+		IsDirect: true,
+		Preset:   directRoomPreset,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "error creating direct room")
+	}
+
+	direct[userID] = append(direct[userID], room.RoomID)
+
+	if err := b.cli.MakeRequest(http.MethodPut, b.cli.BuildURL("user", b.userID, "account_data", DirectEvent),
+		direct, nil); err != nil {
+		return "", errors.Wrap(err, "error saving direct rooms")
+	}
+
+	return room.RoomID, nil
+}
+
+// isDirectRoom reports whether only the bot and the user are in the room. The user may be invited and not joined yet.
+func (b *Bot) isDirectRoom(roomID, userID string) (bool, error) {
+	members, err := b.cli.JoinedMembers(roomID)
+	if err != nil {
+		return false, errors.Wrap(err, "error getting room members")
+	}
+
+	for member := range members.Joined {
+		if member != b.userID && member != userID {
+			return false, nil
+		}
+	}
+
+	_, joined := members.Joined[b.userID]
+
+	return joined, nil
+}
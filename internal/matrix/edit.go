@@ -0,0 +1,343 @@
+package matrix
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matrix-org/gomatrix"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+const (
+	startOption   = "start"
+	endOption     = "end"
+	holdersOption = "holders"
+	// noEnd is the value of the end option that clears the end of a shift, so it is in progress again.
+	noEnd = "none"
+
+	editHoldersSeparator = ", "
+)
+
+// editShift corrects the start time, end time or holder of an existing shift and records the changes in its history.
+// The new times are checked for conflicts with the other shifts of the track like scheduled shifts.
+//
+//nolint:funlen,cyclop
+func (b *Bot) editShift(event *gomatrix.Event, parts []string) error {
+	if len(parts) < minEditShiftLength {
+		return b.invalidEditShift(event)
+	}
+
+	shiftID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return b.invalidEditShiftWithError(event, err)
+	}
+
+	start, startFound, _ := option(parts, startOption)
+	end, endFound, _ := option(parts, endOption)
+	_, holdersFound, _ := option(parts, holdersOption)
+
+	if !startFound && !endFound && !holdersFound {
+		return b.invalidEditShift(event)
+	}
+
+	shift, err := b.shiftRepo.Find(event.RoomID, shiftID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if _, err := b.cli.SendFormattedText(event.RoomID, "", fmt.Sprintf(ShiftNotFound, shiftID)); err != nil {
+			return errors.Wrap(err, "error sending shift not found message")
+		}
+
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "error getting shift")
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
+	edited := *shift
+
+	if startFound {
+		if edited.StartTime, err = time.ParseInLocation(dateTimeLayout, start, loc); err != nil {
+			return b.invalidEditShiftWithError(event, err)
+		}
+	}
+
+	switch {
+	case endFound && strings.EqualFold(end, noEnd):
+		edited.EndTime = nil
+	case endFound:
+		at, err := time.ParseInLocation(dateTimeLayout, end, loc)
+		if err != nil {
+			return b.invalidEditShiftWithError(event, err)
+		}
+
+		edited.EndTime = &at
+	}
+
+	if holdersFound {
+		holders := mentionsOf(event)
+		if len(holders) == 0 {
+			return b.invalidEditShiftWithError(event, errors.New("mention the new holders"))
+		}
+
+		edited.Holders = shiftHolders(holders)
+	}
+
+	now := time.Now()
+
+	if edited.StartTime.After(now) || (edited.EndTime != nil && edited.EndTime.After(now)) {
+		return b.invalidEditShiftWithError(event, errors.Errorf("start and end can't be in the future, use %s instead",
+			ScheduleShift))
+	}
+
+	if edited.EndTime != nil && !edited.StartTime.Before(*edited.EndTime) {
+		return b.invalidEditShiftWithError(event, errors.New("start must be before end"))
+	}
+
+	if startFound || endFound {
+		conflicts, err := b.editConflicts(&edited, now)
+		if err != nil {
+			return err
+		}
+
+		if len(conflicts) > 0 {
+			message := fmt.Sprintf(ScheduledShiftConflict, strings.Join(conflicts, ", "))
+
+			if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+				return errors.Wrap(err, "error sending edited shift conflict message")
+			}
+
+			return nil
+		}
+	}
+
+	edits := shiftEdits(shift, &edited, event.Sender)
+
+	if err := b.shiftRepo.Edit(&edited, edits); err != nil {
+		return errors.Wrap(err, "error editing shift")
+	}
+
+	changes := ""
+
+	for _, edit := range edits {
+		item, err := b.shiftEditItem(edit, loc)
+		if err != nil {
+			return err
+		}
+
+		changes += item
+	}
+
+	message := fmt.Sprintf(ShiftEdited, shiftID, changes, ShiftHistory, shiftID)
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending shift edited message")
+	}
+
+	if !holdersFound {
+		return nil
+	}
+
+	to := now
+	if edited.EndTime != nil {
+		to = *edited.EndTime
+	}
+
+	return b.warnAbsentees(event, edited.HolderIDs(), edited.StartTime, to)
+}
+
+// editConflicts returns the ids of the other shifts of the track that overlap the edited shift. A shift without an end
+// is in progress until now.
+func (b *Bot) editConflicts(edited *model.Shift, now time.Time) ([]string, error) {
+	end := now
+	if edited.EndTime != nil {
+		end = *edited.EndTime
+	}
+
+	overlapping, err := b.shiftRepo.Overlapping(edited.RoomID, edited.StartTime, end)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting overlapping shifts")
+	}
+
+	conflicts := make([]string, 0)
+
+	for _, shift := range model.InTrack(overlapping, edited.Track) {
+		// Shifts without an end are ended by a person and are not taken as a conflict.
+		if shift.ID != edited.ID && (shift.EndTime != nil || shift.PlannedEndTime != nil) {
+			conflicts = append(conflicts, strconv.Itoa(shift.ID))
+		}
+	}
+
+	return conflicts, nil
+}
+
+// shiftHistory lists the edits of a shift.
+func (b *Bot) shiftHistory(event *gomatrix.Event, parts []string) error {
+	if len(parts) < minShiftHistoryLength {
+		return ErrInvalidCommand
+	}
+
+	shiftID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return errors.Wrap(err, "invalid shift id")
+	}
+
+	if _, err := b.shiftRepo.Find(event.RoomID, shiftID); errors.Is(err, gorm.ErrRecordNotFound) {
+		if _, err := b.cli.SendFormattedText(event.RoomID, "", fmt.Sprintf(ShiftNotFound, shiftID)); err != nil {
+			return errors.Wrap(err, "error sending shift not found message")
+		}
+
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "error getting shift")
+	}
+
+	edits, err := b.shiftEditRepo.Get(shiftID)
+	if err != nil {
+		return errors.Wrap(err, "error getting shift edits")
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
+	message := ""
+
+	for _, edit := range edits {
+		item, err := b.shiftEditItem(edit, loc)
+		if err != nil {
+			return err
+		}
+
+		message += item
+	}
+
+	message = fmt.Sprintf(ShiftEditList, shiftID, message)
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending shift history")
+	}
+
+	return nil
+}
+
+func (b *Bot) shiftEditItem(edit model.ShiftEdit, loc *time.Location) (string, error) {
+	sender, err := b.mention(edit.Sender)
+	if err != nil {
+		return "", err
+	}
+
+	oldValue, err := b.shiftEditValue(edit.Field, edit.OldValue, loc)
+	if err != nil {
+		return "", err
+	}
+
+	newValue, err := b.shiftEditValue(edit.Field, edit.NewValue, loc)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(ShiftEditItem, sender, edit.Field, oldValue, newValue,
+		formatTime(edit.CreatedAt, loc)), nil
+}
+
+// shiftEditValue renders a value of the shift history.
+func (b *Bot) shiftEditValue(field, value string, loc *time.Location) (string, error) {
+	if value == "" {
+		return "-", nil
+	}
+
+	if field == model.ShiftFieldHolders {
+		holders := strings.Split(value, editHoldersSeparator)
+
+		for i, holder := range holders {
+			id, tier, _ := strings.Cut(holder, " ")
+
+			mention, err := b.mention(id)
+			if err != nil {
+				return "", err
+			}
+
+			holders[i] = strings.TrimSpace(mention + " " + tier)
+		}
+
+		return strings.Join(holders, editHoldersSeparator), nil
+	}
+
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value, nil //nolint:nilerr
+	}
+
+	return formatTime(at, loc), nil
+}
+
+// shiftEdits returns the changes between the old and the new version of a shift.
+func shiftEdits(old, edited *model.Shift, sender string) []model.ShiftEdit {
+	edits := make([]model.ShiftEdit, 0)
+	now := time.Now()
+
+	add := func(field, oldValue, newValue string) {
+		if oldValue == newValue {
+			return
+		}
+
+		edits = append(edits, model.ShiftEdit{
+			ShiftID:   old.ID,
+			Sender:    sender,
+			Field:     field,
+			OldValue:  oldValue,
+			NewValue:  newValue,
+			CreatedAt: now,
+		})
+	}
+
+	add(model.ShiftFieldStart, editTime(&old.StartTime), editTime(&edited.StartTime))
+	add(model.ShiftFieldEnd, editTime(old.EndTime), editTime(edited.EndTime))
+	add(model.ShiftFieldHolders, editHolders(old.Holders), editHolders(edited.Holders))
+
+	return edits
+}
+
+// editHolders formats the holders of a shift for its history, like: @a:matrix.org (primary), @b:matrix.org (secondary).
+func editHolders(holders []model.ShiftHolder) string {
+	res := make([]string, 0, len(holders))
+
+	for _, holder := range holders {
+		res = append(res, fmt.Sprintf(TieredHolder, holder.Holder, holder.Tier))
+	}
+
+	return strings.Join(res, editHoldersSeparator)
+}
+
+func editTime(at *time.Time) string {
+	if at == nil {
+		return ""
+	}
+
+	return at.Format(time.RFC3339)
+}
+
+func (b *Bot) invalidEditShift(event *gomatrix.Event) error {
+	if _, err := b.cli.SendText(event.RoomID, InvalidEditShiftCommand); err != nil {
+		return errors.Wrap(err, "error sending invalid edit shift command message")
+	}
+
+	return nil
+}
+
+func (b *Bot) invalidEditShiftWithError(event *gomatrix.Event, err error) error {
+	if _, err := b.cli.SendText(event.RoomID, fmt.Sprintf(InvalidEditShiftCommandWithError, err.Error())); err != nil {
+		return errors.Wrap(err, "error sending invalid edit shift command message")
+	}
+
+	return nil
+}
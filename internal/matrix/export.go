@@ -0,0 +1,141 @@
+package matrix
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	formatOption = "format"
+	csvFormat    = "csv"
+	jsonFormat   = "json"
+
+	csvContentType  = "text/csv"
+	jsonContentType = "application/json"
+
+	hundredths = 100
+)
+
+// exportFormats are the formats that a report can be exported in.
+//
+//nolint:gochecknoglobals
+var exportFormats = map[string]bool{csvFormat: true, jsonFormat: true}
+
+// exportedReport is a report in the exported files. The covered time is in decimal hours, so it can be calculated
+// with.
+type exportedReport struct {
+	From         string         `json:"from"`
+	To           string         `json:"to"`
+	Weekend      string         `json:"weekend"`
+	Holidays     int            `json:"holidays"`
+	WorkingHours string         `json:"working_hours"`
+	Items        []exportedItem `json:"items"`
+}
+
+type exportedItem struct {
+	Holder        string  `json:"holder"`
+	DisplayName   string  `json:"display_name"`
+	Track         string  `json:"track"`
+	BusinessHours float64 `json:"business_hours"`
+	OffHours      float64 `json:"off_hours"`
+	RestDayHours  float64 `json:"rest_day_hours"`
+	Total         float64 `json:"total_hours"`
+	WorkingDays   int     `json:"working_days"`
+	RestDays      int     `json:"rest_days"`
+}
+
+func newExportedReport(report ShiftReportTemplate) exportedReport {
+	res := exportedReport{
+		From:         report.From,
+		To:           report.To,
+		Weekend:      report.Weekend,
+		Holidays:     report.Holidays,
+		WorkingHours: report.WorkingHours,
+		Items:        make([]exportedItem, 0, len(report.Items)),
+	}
+
+	for _, item := range report.Items {
+		res.Items = append(res.Items, exportedItem{
+			Holder:        item.Holder,
+			DisplayName:   item.DisplayName,
+			Track:         item.Track,
+			BusinessHours: decimalHours(item.Coverage.BusinessHours),
+			OffHours:      decimalHours(item.Coverage.OffHours),
+			RestDayHours:  decimalHours(item.Coverage.RestDayHours()),
+			Total:         decimalHours(item.Coverage.Total()),
+			WorkingDays:   item.Coverage.WorkingDays,
+			RestDays:      item.Coverage.RestDays,
+		})
+	}
+
+	return res
+}
+
+// decimalHours returns the duration in hours, rounded to hundredths.
+func decimalHours(duration time.Duration) float64 {
+	return math.Round(duration.Hours()*hundredths) / hundredths
+}
+
+// exportReport sends the report as a file in the format to the room. The covered time is exported in decimal hours.
+func (b *Bot) exportReport(roomID, format, name string, report ShiftReportTemplate) error {
+	exported := newExportedReport(report)
+
+	var (
+		content     []byte
+		contentType string
+		err         error
+	)
+
+	switch format {
+	case csvFormat:
+		content, err = reportCSV(exported)
+		contentType = csvContentType
+	case jsonFormat:
+		content, err = json.MarshalIndent(exported, "", "  ")
+		contentType = jsonContentType
+	default:
+		return errors.Errorf("unknown report format %s", format)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "error exporting report")
+	}
+
+	return b.sendFile(roomID, name+"."+format, contentType, content)
+}
+
+// reportCSV returns the items of the report as CSV rows with the MXIDs of the holders.
+func reportCSV(report exportedReport) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writer := csv.NewWriter(&buf)
+
+	records := [][]string{{
+		"holder", "display_name", "track", "business_hours", "off_hours", "rest_day_hours", "total_hours",
+		"working_days", "rest_days",
+	}}
+
+	for _, item := range report.Items {
+		records = append(records, []string{
+			item.Holder, item.DisplayName, item.Track, formatDecimal(item.BusinessHours), formatDecimal(item.OffHours),
+			formatDecimal(item.RestDayHours), formatDecimal(item.Total),
+			strconv.Itoa(item.WorkingDays), strconv.Itoa(item.RestDays),
+		})
+	}
+
+	if err := writer.WriteAll(records); err != nil {
+		return nil, errors.Wrap(err, "error writing csv")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func formatDecimal(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
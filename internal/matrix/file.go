@@ -0,0 +1,161 @@
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/matrix-org/gomatrix"
+	"github.com/pkg/errors"
+)
+
+const (
+	// FileMessage is the message type of the uploaded files.
+	FileMessage = "m.file"
+
+	// maxFileSize is the size limit of the files that the bot downloads.
+	maxFileSize = 10 << 20
+)
+
+var (
+	ErrNoRepliedFile = errors.New("no replied file")
+	ErrFileTooLarge  = errors.Errorf("file is larger than %d MiB", maxFileSize>>20)
+
+	errNotFound = errors.New("file not found")
+
+	// replyRegexp matches the quote of the replied message in the formatted body of a reply.
+	replyRegexp = regexp.MustCompile(`(?s)<mx-reply>.*?</mx-reply>`)
+)
+
+// stripReply removes the quote of the replied message from the body of a reply, which clients add as a fallback in
+// lines that start with "> " before the reply itself.
+func stripReply(body string) string {
+	if !strings.HasPrefix(body, "> ") {
+		return body
+	}
+
+	lines := strings.Split(body, "\n")
+
+	for i, line := range lines {
+		if !strings.HasPrefix(line, ">") {
+			return strings.TrimSpace(strings.Join(lines[i:], "\n"))
+		}
+	}
+
+	return ""
+}
+
+// repliedEventID returns the id of the event that the event replies to, or an empty string if it is not a reply.
+func repliedEventID(event *gomatrix.Event) string {
+	relatesTo, _ := event.Content["m.relates_to"].(map[string]interface{})
+	inReplyTo, _ := relatesTo["m.in_reply_to"].(map[string]interface{})
+	eventID, _ := inReplyTo["event_id"].(string)
+
+	return eventID
+}
+
+// repliedFile returns the name and the content of the file that the event replies to.
+func (b *Bot) repliedFile(event *gomatrix.Event) (string, []byte, error) {
+	eventID := repliedEventID(event)
+	if eventID == "" {
+		return "", nil, ErrNoRepliedFile
+	}
+
+	var replied gomatrix.Event
+
+	if err := b.cli.MakeRequest(http.MethodGet, b.cli.BuildURL("rooms", event.RoomID, "event", eventID),
+		nil, &replied); err != nil {
+		return "", nil, errors.Wrap(err, "error getting replied event")
+	}
+
+	msgType, _ := replied.Content["msgtype"].(string)
+	mxc, _ := replied.Content["url"].(string)
+	name, _ := replied.Content["body"].(string)
+
+	if msgType != FileMessage || mxc == "" {
+		return "", nil, ErrNoRepliedFile
+	}
+
+	content, err := b.download(mxc)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return name, content, nil
+}
+
+// sendFile uploads the content to the content repository and sends it to the room as a file.
+func (b *Bot) sendFile(roomID, name, contentType string, content []byte) error {
+	upload, err := b.cli.UploadToContentRepo(bytes.NewReader(content), contentType, int64(len(content)))
+	if err != nil {
+		return errors.Wrap(err, "error uploading file")
+	}
+
+	if _, err := b.cli.SendMessageEvent(roomID, RoomMessageEvent, gomatrix.FileMessage{
+		MsgType:  FileMessage,
+		Body:     name,
+		URL:      upload.ContentURI,
+		Filename: name,
+		Info:     gomatrix.FileInfo{Mimetype: contentType, Size: uint(len(content))},
+	}); err != nil {
+		return errors.Wrap(err, "error sending file")
+	}
+
+	return nil
+}
+
+// download returns the content of a file of the content repository by its mxc:// URL. The authenticated media API is
+// tried first and the legacy one is used for the servers that don't have it.
+func (b *Bot) download(mxc string) ([]byte, error) {
+	parsed, err := url.Parse(mxc)
+	if err != nil || parsed.Scheme != "mxc" {
+		return nil, errors.Errorf("invalid content url %s", mxc)
+	}
+
+	server, mediaID := parsed.Host, strings.TrimPrefix(parsed.Path, "/")
+
+	content, err := b.get(b.cli.BuildBaseURL("_matrix", "client", "v1", "media", "download", server, mediaID))
+	if errors.Is(err, errNotFound) {
+		content, err = b.get(b.cli.BuildBaseURL("_matrix", "media", "r0", "download", server, mediaID))
+	}
+
+	return content, err
+}
+
+func (b *Bot) get(address string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, address, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating download request")
+	}
+
+	req.Header.Set("Authorization", "Bearer "+b.cli.AccessToken)
+
+	resp, err := b.cli.Client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "error downloading file")
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errNotFound
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("error downloading file: %s", resp.Status)
+	}
+
+	// One more byte than the limit is read to tell the files of the limit size from the larger ones.
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxFileSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading file")
+	}
+
+	if len(content) > maxFileSize {
+		return nil, ErrFileTooLarge
+	}
+
+	return content, nil
+}
@@ -0,0 +1,136 @@
+package matrix
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/matrix-org/gomatrix"
+	"github.com/pkg/errors"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/accounting"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/ical"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+const (
+	listHolidays   = "list"
+	importHolidays = "import"
+
+	// holidaysListDays is how many days from today !holidays lists.
+	holidaysListDays = 365
+	holidayLayout    = "Monday, 2006-01-02"
+)
+
+// holidays lists the upcoming holidays with !holidays, or imports them from a replied .ics file with !holidays import.
+func (b *Bot) holidays(event *gomatrix.Event, parts []string) error {
+	if len(parts) < minHolidaysLength || strings.EqualFold(parts[1], listHolidays) {
+		return b.listHolidays(event)
+	}
+
+	if strings.EqualFold(parts[1], importHolidays) {
+		return b.importHolidays(event)
+	}
+
+	return b.invalidHolidaysWithError(event, errors.Errorf("unknown subcommand %s", parts[1]))
+}
+
+// importHolidays saves every day of the events of the replied calendar file as a holiday of the room. Only the admins
+// of the room can import holidays, as they change the hours and the pays of the reports.
+func (b *Bot) importHolidays(event *gomatrix.Event) error {
+	admin, err := b.requireAdmin(event, "import holidays")
+	if err != nil || !admin {
+		return err
+	}
+
+	name, content, err := b.repliedFile(event)
+	if errors.Is(err, ErrNoRepliedFile) {
+		return b.invalidHolidaysWithError(event, errors.New("reply to an uploaded .ics file"))
+	} else if errors.Is(err, ErrFileTooLarge) {
+		return b.invalidHolidaysWithError(event, err)
+	} else if err != nil {
+		return err
+	}
+
+	loc, err := b.roomLocation(event.RoomID)
+	if err != nil {
+		return err
+	}
+
+	events, err := ical.Parse(bytes.NewReader(content), loc)
+	if err != nil {
+		return b.invalidHolidaysWithError(event, err)
+	}
+
+	holidays := model.CalendarHolidays(event.RoomID, events)
+
+	if err := b.holidayRepo.Save(holidays); err != nil {
+		return errors.Wrap(err, "error saving holidays")
+	}
+
+	message := fmt.Sprintf(HolidaysImported, len(holidays), name)
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending holidays imported message")
+	}
+
+	return nil
+}
+
+func (b *Bot) listHolidays(event *gomatrix.Event) error {
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().In(loc)
+
+	holidays, err := b.holidayRepo.Get(event.RoomID, now, now.AddDate(0, 0, holidaysListDays))
+	if err != nil {
+		return errors.Wrap(err, "error getting holidays")
+	}
+
+	message := ""
+
+	for _, item := range holidays {
+		scope := "this room"
+		if item.RoomID == model.OrganizationHolidays {
+			scope = "organization"
+		}
+
+		message += fmt.Sprintf(HolidayItem, item.Date.Format(holidayLayout), item.Name, scope)
+	}
+
+	message = fmt.Sprintf(HolidayList, holidaysListDays, message, Holidays, importHolidays)
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending holidays list")
+	}
+
+	return nil
+}
+
+// calendar returns the calendar of the room with its holidays (and the holidays of the organization) between the from
+// and to days.
+func (b *Bot) calendar(room *model.Room, from, to time.Time) (accounting.Calendar, error) {
+	holidays, err := b.holidayRepo.Get(room.ID, from, to)
+	if err != nil {
+		return accounting.Calendar{}, errors.Wrap(err, "error getting holidays")
+	}
+
+	calendar, err := accounting.NewCalendar(room, holidays)
+	if err != nil {
+		return calendar, errors.Wrap(err, "error creating calendar")
+	}
+
+	return calendar, nil
+}
+
+func (b *Bot) invalidHolidaysWithError(event *gomatrix.Event, err error) error {
+	if _, err := b.cli.SendText(event.RoomID, fmt.Sprintf(InvalidHolidaysCommandWithError, err.Error())); err != nil {
+		return errors.Wrap(err, "error sending invalid holidays command message")
+	}
+
+	return nil
+}
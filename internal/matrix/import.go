@@ -0,0 +1,124 @@
+package matrix
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matrix-org/gomatrix"
+	"github.com/pkg/errors"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/schedule"
+)
+
+const confirmImport = "confirm"
+
+// importShifts previews the shifts of the replied .csv or .ics file with their conflicts. The valid shifts are
+// scheduled when the command is repeated with confirm, after they are checked again. Only the admins of the room can
+// import shifts and a file can be confirmed only after it is previewed.
+func (b *Bot) importShifts(event *gomatrix.Event, parts []string) error {
+	admin, err := b.requireAdmin(event, "import shifts")
+	if err != nil || !admin {
+		return err
+	}
+
+	name, content, err := b.repliedFile(event)
+	if errors.Is(err, ErrNoRepliedFile) {
+		return b.invalidImportShiftsWithError(event, errors.New("reply to an uploaded .csv or .ics file"))
+	} else if errors.Is(err, ErrFileTooLarge) {
+		return b.invalidImportShiftsWithError(event, err)
+	} else if err != nil {
+		return err
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
+	shifts, err := schedule.Parse(name, content, event.RoomID, event.Sender, loc)
+	if err != nil {
+		return b.invalidImportShiftsWithError(event, err)
+	}
+
+	proposals, err := b.importer.Preview(shifts, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "error previewing shifts")
+	}
+
+	var message string
+
+	key := event.RoomID + " " + repliedEventID(event)
+
+	b.importsLock.Lock()
+	defer b.importsLock.Unlock()
+
+	switch {
+	case len(parts) > 1 && strings.EqualFold(parts[1], confirmImport) && !b.previewedImports[key]:
+		message = fmt.Sprintf(ImportNotPreviewed, name, ImportShifts)
+	case len(parts) > 1 && strings.EqualFold(parts[1], confirmImport):
+		count, err := b.importer.Commit(proposals)
+		if err != nil {
+			return errors.Wrap(err, "error importing shifts")
+		}
+
+		delete(b.previewedImports, key)
+
+		message = fmt.Sprintf(ShiftsImported, count, name, len(proposals)-count)
+	default:
+		message, err = b.importPreview(name, proposals, loc)
+		if err != nil {
+			return err
+		}
+
+		b.previewedImports[key] = true
+	}
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending import shifts message")
+	}
+
+	return nil
+}
+
+func (b *Bot) importPreview(name string, proposals []schedule.Proposal, loc *time.Location) (string, error) {
+	items := ""
+	valid := 0
+
+	for _, proposal := range proposals {
+		holders, err := b.holdersText(proposal.Shift.Holders)
+		if err != nil {
+			return "", err
+		}
+
+		status := ""
+
+		switch {
+		case len(proposal.Conflicts) > 0:
+			ids := make([]string, 0, len(proposal.Conflicts))
+			for _, id := range proposal.Conflicts {
+				ids = append(ids, strconv.Itoa(id))
+			}
+
+			status = fmt.Sprintf(ImportConflict, strings.Join(ids, ", "))
+		case proposal.Problem != "":
+			status = fmt.Sprintf(ImportProblem, proposal.Problem)
+		default:
+			valid++
+		}
+
+		items += fmt.Sprintf(ImportItem, formatTime(proposal.Shift.StartTime, loc),
+			formatTime(*proposal.Shift.PlannedEndTime, loc), holders, trackName(proposal.Shift.Track), status)
+	}
+
+	return fmt.Sprintf(ImportPreview, valid, name, len(proposals)-valid, items, ImportShifts, confirmImport), nil
+}
+
+func (b *Bot) invalidImportShiftsWithError(event *gomatrix.Event, err error) error {
+	if _, err := b.cli.SendText(event.RoomID, fmt.Sprintf(InvalidImportShiftsCommandWithError, err.Error())); err != nil {
+		return errors.Wrap(err, "error sending invalid import shifts command message")
+	}
+
+	return nil
+}
@@ -1,56 +1,275 @@
 package matrix
 
 import (
-	"text/template"
+	"html/template"
 )
 
 //nolint:gochecknoglobals
-var reportTemplate = template.Must(template.New("tmpl").Parse(ReportMessage))
+var (
+	reportTemplate  = template.Must(template.New("tmpl").Parse(ReportMessage))
+	summaryTemplate = template.Must(template.New("summary").Parse(ShiftSummaryMessage))
+	payTemplate     = template.Must(template.New("pay").Parse(PayReportMessage))
+)
 
 //nolint:lll
 const (
-	ShiftStarted         = `%s shift started at %s.`
-	ShiftItem            = "<li>%s <b>Start time</b>: %s | <b>End time</b>: %s</li> | <b>Holders</b>: %s | <b>id</b>: %d"
-	ShiftList            = `<ol>%s</ol>`
-	ShiftEndFormatted    = "Shift with id: <b>%d</b> ended. Good job! :)"
-	ShiftEnd             = "Shift with id %d ended."
-	InvalidShiftStart    = "Please mention the on call people."
+	ShiftStarted      = `%s shift started at %s.`
+	ShiftItem         = "<li>%s <b>Start time</b>: %s | <b>End time</b>: %s</li> | <b>Holders</b>: %s | <b>Track</b>: %s | <b>id</b>: %d"
+	TieredHolder      = "%s (%s)"
+	PlannedEnd        = "%s (planned)"
+	ShiftList         = `<ol start="%d">%s</ol>`
+	ShiftListPage     = "Shifts %d-%d of %d."
+	ShiftListNextPage = " Next page: %s %s=%d"
+	NoShiftFound      = "No shifts found (%d in total)."
+	ShiftEndFormatted = "Shift with id: <b>%d</b> ended. Good job! :)"
+	ShiftEnd          = "Shift with id %d ended."
+	ShiftNotStarted   = "Shift with id: <b>%d</b> is not started yet and can't end before its start. Cancel it with %s %d instead."
+	ShiftCoveredBy    = " (covered by %s until %s)"
+	ShiftHandedOff    = "Shift handed off to %s at %s. New shift id: <b>%d</b>. %d open follow ups carried over, list them with %s."
+	InvalidShiftStart = "Please mention the on call people."
+	ShiftScheduled    = "Shift scheduled for %s from %s to %s. Shift id: <b>%d</b>."
+	NotAdmin          = "Only the admins of this room (power level %d) can %s."
+
+	ScheduledShiftConflict = "The shift conflicts with the shifts with ids: <b>%s</b>."
+	ScheduledShiftStarted  = "Scheduled shift with id: <b>%d</b> on track %s started. %s is on call until %s."
+	ScheduledShiftEnded    = "Scheduled shift with id: <b>%d</b> of %s ended. Good job! :)"
+
+	OverrideCreated      = "%s covers %s from %s to %s on the shift with id: <b>%d</b>. Override id: <b>%d</b>."
+	NoShiftToOverride    = "%s has no shift in this room between %s and %s."
 	ActiveShiftOngoing   = "There's an active shift still in progress. You can't start a new one."
 	NoActiveShiftOngoing = "There's no active shift. Create one first."
+	AmbiguousTrack       = "Shifts of several tracks are active (%s). Choose one of them with %s=<name>."
 	FollowUpCreated      = "Follow up created. List all follow ups with %s or mark this follow up as resolved by %s %d"
 	FollowUpItem         = "<li>%s <b>id</b>: %d | <b>Category</b>: %s</li> | " +
 		"<b>Initiator</b>: %s</li> | <b>Description</b>: %s | <b>Created at</b>: %s"
 	FollowUpList     = `<ol>%s</ol>`
 	FollowUpResolved = "Follow up with id: <b>%d</b>, marked as resolved."
+	NoteCreated      = "Note saved on the shift with id: <b>%d</b>. It will be shown in the handover summary."
 	HelpList         = `
 <h2>Shift commands:</h2>
 <ul>
-<li>!startshift &lt;mentioned oncalls&gt; <b>=&gt;</b> start a new shift for the sender of the message or if anyone is mentioned, start shift for the mentioned people</li>
-<li>!listshifts <b>=&gt;</b> list all shifts</li>
-<li>!endshift &lt;shift id&gt; <b>=&gt;</b> end a shift</li>
+<li>!startshift [track=&lt;name&gt;] &lt;mentioned oncalls&gt; <b>=&gt;</b> start a new shift for the sender of the message or if anyone is mentioned, start shift for the mentioned people. Write a tier (primary, secondary or manager) before the mentioned people to set their escalation tier, like: !startshift primary @a secondary @b. Each track (like: track=db) of a room can have its own active shift</li>
+<li>!listshifts [active] [mentioned holder] [from yyyy-mm-dd] [to yyyy-mm-dd] [track=&lt;name&gt;] [page=&lt;n&gt;] <b>=&gt;</b> list the shifts, newest first, 10 in each page. The shifts can be filtered by being in progress, a holder, a date range and a track</li>
+<li>!suggest [track=&lt;name&gt;] [days=&lt;n&gt;] [mentioned candidates] <b>=&gt;</b> suggest the next holder based on the working days, holidays and time since the last shift of each person in the last 90 (or n) days</li>
+<li>!oncall [room id or alias] <b>=&gt;</b> show who is on call right now in this room or another room of the bot</li>
+<li>!scheduleshift [track=&lt;name&gt;] &lt;mentioned oncalls&gt; &lt;start: yyyy-mm-ddTHH:MM&gt; &lt;end: yyyy-mm-ddTHH:MM&gt; <b>=&gt;</b> schedule a shift in the future. The bot starts and ends it on time</li>
+<li>!importshifts [confirm] <b>=&gt;</b> reply to an uploaded .csv (start,end,holder[,tier][,track]) or .ics file to preview its shifts with their conflicts, and schedule them by repeating the command with confirm</li>
+<li>!endshift &lt;shift id&gt; <b>=&gt;</b> end a shift and post its handover summary</li>
+<li>!override &lt;mentioned coverer&gt; [mentioned covered] &lt;from: yyyy-mm-ddTHH:MM&gt; &lt;to: yyyy-mm-ddTHH:MM&gt; <b>=&gt;</b> let someone cover a time window of the shift of the sender (or of the second mentioned person)</li>
+<li>!editshift &lt;shift id&gt; [start=yyyy-mm-ddTHH:MM] [end=yyyy-mm-ddTHH:MM|none] [holders=&lt;mentioned holders&gt;] <b>=&gt;</b> correct the start, end or holders of a shift that was started or ended at the wrong time (end=none reopens it)</li>
+<li>!cancelshift &lt;shift id&gt; [confirm] <b>=&gt;</b> cancel a shift that was created by mistake, together with its follow ups. The shift is cancelled only when the command is repeated with confirm</li>
+<li>!shifthistory &lt;shift id&gt; <b>=&gt;</b> show who changed a shift, when and what the old and new values were</li>
+<li>!handoff [track=&lt;name&gt;] &lt;mentioned next oncalls&gt; <b>=&gt;</b> end the active shift and start a new one for the mentioned people (or the sender) at once, carrying over open follow ups</li>
 </ul>
 <br>
 <h2>Follow up commands:</h2>
 <ul>
-<li>!followup &lt;category: incoming|outgoing&gt; &lt;initiator&gt; &lt;description&gt; <b>=&gt;</b> create a new follow up</li>
-<li>!listfollowups <b>=&gt;</b> list all follow ups</li>
+<li>!followup [track=&lt;name&gt;] &lt;category: incoming|outgoing&gt; &lt;initiator&gt; &lt;description&gt; <b>=&gt;</b> create a new follow up</li>
+<li>!listfollowups [track=&lt;name&gt;] <b>=&gt;</b> list all follow ups</li>
 <li>!resolvefollowup <id> <b>=&gt;</b> resolve a follow up</li>
-<li>!report<b>=&gt;</b> Report current room on-call days for this month or within a custom time range</li>
+<li>!note [track=&lt;name&gt;] &lt;text&gt; <b>=&gt;</b> leave a note on the active shift for its handover summary</li>
+<li>!report [track=&lt;name&gt;] [format=csv|json]<b>=&gt;</b> Report current room on-call days for this month or within a custom time range, grouped by track. The TO day is included. The covered hours are split into business hours, off-hours and rest days by the working hours, the weekend and the holidays of the room. With format, the report is sent as a CSV or JSON file with the MXIDs of the holders</li>
+<li>!report pay [FROM yyyy-mm-dd] [TO yyyy-mm-dd] <b>=&gt;</b> calculate the pay of each holder for this month or a custom time range, the TO day included, by the hourly rates of the business hours, off-hours, weekends and holidays (!set rates off_hours=10 weekend=20 holiday=30)</li>
+</ul>
+<br>
+<h2>Swap commands:</h2>
+<ul>
+<li>!swap request &lt;shift id&gt; &lt;mentioned colleague&gt; <b>=&gt;</b> ask a colleague to take over a shift from the sender, who must be one of its holders. The shift is handed over only if the colleague accepts</li>
+<li>!swap accept &lt;id&gt; <b>=&gt;</b> accept a swap request (or react with 👍 to the request)</li>
+<li>!swap decline &lt;id&gt; <b>=&gt;</b> decline a swap request (or react with 👎 to the request)</li>
+<li>!swap list <b>=&gt;</b> list all swaps</li>
+</ul>
+<br>
+<h2>Rotation commands:</h2>
+<ul>
+<li>!rotation create [track=&lt;name&gt;] &lt;name&gt; &lt;daily|weekly|duration like 72h&gt; &lt;handoff time: HH:MM|yyyy-mm-ddTHH:MM&gt; &lt;mentioned roster&gt; <b>=&gt;</b> create a rotation that hands off the shift to the next person of the roster on every period</li>
+<li>!rotation list <b>=&gt;</b> list all rotations</li>
+<li>!rotation delete &lt;id&gt; <b>=&gt;</b> delete a rotation</li>
+</ul>
+<br>
+<h2>Availability commands:</h2>
+<ul>
+<li>!away &lt;from: yyyy-mm-ddTHH:MM&gt; &lt;to: yyyy-mm-ddTHH:MM&gt; [reason] <b>=&gt;</b> record that the sender is not available. Rotations skip them and the bot warns when someone gives them a shift</li>
+<li>!away list <b>=&gt;</b> list the current and upcoming absences of the members of this room</li>
+<li>!away delete &lt;id&gt; <b>=&gt;</b> delete an absence of the sender</li>
+</ul>
+<br>
+<h2>Setting commands:</h2>
+<ul>
+<li>!settings <b>=&gt;</b> list the settings of the room, like the reminder lead times and the maximum shift duration</li>
+<li>!set &lt;setting&gt; &lt;value&gt; <b>=&gt;</b> change a setting of the room, like: !set start_reminder 30m (only the admins can change the settings of the reports and pays)</li>
+<li>!holidays <b>=&gt;</b> list the upcoming holidays of the room and the organization, which the reports count besides the weekend</li>
+<li>!holidays import <b>=&gt;</b> reply to an uploaded .ics file to import its events as holidays of the room (room admins only)</li>
+<li>!calendar link [mine] <b>=&gt;</b> get the link of the calendar feed of the shifts of this room, or of your shifts in all the rooms with mine (sent in a direct message), to subscribe to it in your calendar app</li>
+<li>!calendar reset [mine] <b>=&gt;</b> replace the link of the calendar feed, so the previous link doesn't work anymore</li>
+<li>!timezone [zone|local] <b>=&gt;</b> show or change the time zone that your times are parsed and shown in, like: !timezone Europe/Berlin (local uses the time zone of the room)</li>
 </ul>
 `
+	HelpWeekend = `<p>The reports count <b>%s</b> as holidays in this room. Change them with %s weekend &lt;days&gt;.</p>`
+
 	ReportMessage = `
 <p>From {{.From}} - To {{.To}}</p>
+<p>Holidays: {{.Weekend}}{{if .Holidays}} and {{.Holidays}} other days{{end}} | Working hours: {{.WorkingHours}}</p>
+<ul>
+{{range $item := .Items}}
+    <li> {{$item.HolderID}} ({{$item.Track}})
+		<ul>
+			<li>Business hours: {{$item.BusinessHours}}</li>
+			<li>Off-hours: {{$item.OffHours}}</li>
+			<li>Rest days: {{$item.RestDayHours}}</li>
+			<li>Total: {{$item.Total}} ({{$item.WorkingDay}} working days, {{$item.Holiday}} holidays)</li>
+		</ul>
+	</li>
+{{end}}
+</ul>
+`
+	PayReportMessage = `
+<p>Pays from {{.From}} - To {{.To}}</p>
+<p>Hourly rates: {{.Rates}}</p>
 <ul>
 {{range $item := .Items}}
-    <li> {{$item.HolderID}}
+	<li> {{$item.HolderID}}: <b>{{$item.Amount}}</b>
+		<ul>
+			<li>Business hours: {{$item.BusinessHours}}</li>
+			<li>Off-hours: {{$item.OffHours}}</li>
+			<li>Weekends: {{$item.WeekendHours}}</li>
+			<li>Holidays: {{$item.HolidayHours}}</li>
+		</ul>
+	</li>
+{{end}}
+</ul>
+<p>Total: <b>{{.Total}}</b></p>
+`
+	ShiftSummaryMessage = `
+<h3>Handover summary of shift {{.ID}}</h3>
+<ul>
+	<li>Holders: {{.Holders}}</li>
+	<li>Track: {{.Track}}</li>
+	<li>Duration: {{.Duration}} ({{.Start}} - {{.End}})</li>
+</ul>
+<h4>Follow ups</h4>
+<ul>
+{{range $category := .Categories}}
+	<li> {{$category.Name}}: {{$category.Open}} open, {{$category.Done}} done
 		<ul>
-			<li>Working day: {{$item.WorkingDay}}</li>
-			<li>Holiday: {{$item.Holiday}}</li>
+		{{range $item := $category.Items}}
+			<li>{{if $item.Done}}✅{{else}}⭕️{{end}} <b>id</b>: {{$item.ID}} | <b>Initiator</b>: {{$item.Initiator}} | <b>Description</b>: {{$item.Description}}</li>
+		{{end}}
 		</ul>
 	</li>
 {{end}}
 </ul>
+<h4>Notes</h4>
+<ul>
+{{range $note := .Notes}}
+	<li>{{$note.Sender}}: {{$note.Body}} ({{$note.CreatedAt}})</li>
+{{end}}
+</ul>
 `
+	SuggestionList        = "Suggested next holder: %s. Load of the candidates in the last %d days, from the least loaded: <ol>%s</ol>"
+	SuggestionItem        = "<li>%s | <b>Working days</b>: %d | <b>Holidays</b>: %d | <b>Last shift</b>: %s | <b>Score</b>: %.1f</li>"
+	NoSuggestion          = "Nobody who is available now held a shift in the last %d days. Mention the candidates to get a suggestion."
+	InvalidSuggestCommand = "Invalid suggest command (invalid days %s). Usage: !suggest [track=<name>] [days=<n>] [mentioned candidates]"
+
+	OnCallList   = "On call in %s right now: <ul>%s</ul>"
+	OnCallItem   = "<li>%s | <b>Tier</b>: %s | <b>Track</b>: %s | <b>Since</b>: %s</li>"
+	NobodyOnCall = "Nobody is on call in %s right now."
+	UnknownRoom  = "I'm not in the room %s."
+
 	InvalidReportCommand          = "Invalid report command"
 	InvalidReportCommandWithError = "Invalid report command (%s)"
+
+	RotationCreated  = "Rotation <b>%s</b> created with id: <b>%d</b>. The first handoff is at %s to %s."
+	RotationItem     = "<li><b>id</b>: %d | <b>Name</b>: %s | <b>Track</b>: %s | <b>Period</b>: %s | <b>Roster</b>: %s | <b>Next handoff</b>: %s to %s</li>"
+	RotationList     = `<ol>%s</ol>`
+	RotationDeleted  = "Rotation with id: <b>%d</b> deleted."
+	RotationNotFound = "There's no rotation with id: <b>%d</b> in this room."
+	RotationHandoff  = "Rotation <b>%s</b>: %s is on call now. Next handoff is at %s to %s."
+
+	InvalidListShiftsCommandWithError = "Invalid list shifts command (%s). Usage: !listshifts [active] [mentioned holder] [from <yyyy-mm-dd>] [to <yyyy-mm-dd>] [track=<name>] [page=<n>]"
+
+	InvalidScheduleShiftCommand          = "Invalid schedule shift command. Usage: !scheduleshift [track=<name>] <mentioned oncalls> <start: yyyy-mm-ddTHH:MM> <end: yyyy-mm-ddTHH:MM>"
+	InvalidScheduleShiftCommandWithError = "Invalid schedule shift command (%s)"
+
+	ImportItem                          = "<li>%s - %s | <b>Holders</b>: %s | <b>Track</b>: %s%s</li>"
+	ImportConflict                      = " | <b>Skipped</b>: conflicts with the shifts with ids: %s"
+	ImportProblem                       = " | <b>Skipped</b>: %s"
+	ImportPreview                       = "%d shifts of <b>%s</b> are going to be scheduled and %d are skipped: <ol>%s</ol>Confirm with %s %s as a reply to the file."
+	ShiftsImported                      = "%d shifts of <b>%s</b> scheduled and %d skipped."
+	ImportNotPreviewed                  = "Preview the import of <b>%s</b> with %s as a reply to the file before confirming it."
+	InvalidImportShiftsCommandWithError = "Invalid import shifts command (%s). Usage: !importshifts [confirm] (as a reply to a .csv or .ics file)"
+
+	SwapRequested      = "%s asks %s to take over their shift with id: <b>%d</b> that starts at %s. %s, react to this message with 👍 to accept or 👎 to decline (or use !swap accept %d / !swap decline %d). Swap id: <b>%d</b>."
+	SwapAccepted       = "Swap with id: <b>%d</b> accepted. %s holds the shift with id: <b>%d</b> now."
+	SwapDeclined       = "Swap with id: <b>%d</b> declined by %s."
+	SwapNotFound       = "There's no swap with id: <b>%d</b> in this room."
+	SwapNotPending     = "Swap with id: <b>%d</b> is already %s."
+	SwapNotTarget      = "Only %s can accept or decline the swap with id: <b>%d</b>."
+	SwapShiftEnded     = "The shift with id: <b>%d</b> is already ended and can't be swapped."
+	SwapShiftCancelled = "The shift with id: <b>%d</b> is cancelled and can't be swapped."
+	SwapNotHolder      = "%s doesn't hold the shift with id: <b>%d</b>. Only its holders can ask a colleague to take it over."
+	SwapItem           = "<li><b>id</b>: %d | <b>Shift</b>: %d | <b>From</b>: %s | <b>To</b>: %s | <b>Status</b>: %s | <b>Requested at</b>: %s</li>"
+	SwapList           = `<ol>%s</ol>`
+	ShiftNotFound      = "There's no shift with id: <b>%d</b> in this room."
+
+	ShiftEdited   = "Shift with id: <b>%d</b> edited: <ul>%s</ul>See all of its changes with %s %d."
+	ShiftEditItem = "<li>%s changed <b>%s</b> from %s to %s at %s</li>"
+	ShiftEditList = "History of the shift with id: <b>%d</b>: <ul>%s</ul>"
+
+	InvalidEditShiftCommand          = "Invalid edit shift command. Usage: !editshift <shift id> [start=<yyyy-mm-ddTHH:MM>] [end=<yyyy-mm-ddTHH:MM|none>] [holders=<mentioned holders>]"
+	InvalidEditShiftCommandWithError = "Invalid edit shift command (%s)"
+
+	ShiftCancelConfirmation   = "You're about to cancel the shift with id: <b>%d</b> of %s that started at %s and its %d follow ups. It won't be listed or counted in the reports anymore. Confirm with %s %d %s."
+	ShiftCancelled            = "Shift with id: <b>%d</b> cancelled."
+	InvalidCancelShiftCommand = "Invalid cancel shift command. Usage: !cancelshift <shift id> [confirm]"
+
+	InvalidSwapCommand = "Invalid swap command. Usage: !swap request <shift id> <mentioned colleague> | !swap accept <id> | !swap decline <id> | !swap list"
+
+	InvalidOverrideCommand          = "Invalid override command. Usage: !override <mentioned coverer> [mentioned covered] <from: yyyy-mm-ddTHH:MM> <to: yyyy-mm-ddTHH:MM>"
+	InvalidOverrideCommandWithError = "Invalid override command (%s)"
+
+	ShiftStartReminder    = "%s, your shift with id: <b>%d</b> on track %s starts at %s."
+	RotationStartReminder = "%s, your shift of rotation <b>%s</b> on track %s starts at %s."
+	ShiftEndReminder      = "%s, your shift with id: <b>%d</b> on track %s ends at %s. Time to prepare the handover, leave notes for the next holders with %s."
+
+	OverdueShiftWarning = "%s, the shift with id: <b>%d</b> is going to pass the maximum shift duration of this room (%s) and it will be ended automatically at %s. End it with %s or hand it off with %s if you're done."
+	OverdueShiftEnded   = "Shift with id: <b>%d</b> passed the maximum shift duration of this room (%s) and it is ended automatically."
+
+	AwayCreated  = "%s is away from %s to %s. Away id: <b>%d</b>."
+	AwayItem     = "<li><b>id</b>: %d | %s | <b>From</b>: %s | <b>To</b>: %s | <b>Reason</b>: %s</li>"
+	AwayList     = `<ol>%s</ol>`
+	AwayDeleted  = "Away with id: <b>%d</b> deleted."
+	AwayNotFound = "You have no away with id: <b>%d</b>."
+	AwayWarning  = "%s, heads up: %s is away from %s to %s (reason: %s)."
+	// HiddenAwayReason is shown instead of the reasons of the absences that are recorded in other rooms.
+	HiddenAwayReason = "recorded in another room"
+
+	RotationHolderAway  = "%s is away, so %s takes this turn of rotation <b>%s</b>."
+	SwapTargetAway      = "%s is away from %s to %s (reason: %s) and can't take over the shift with id: <b>%d</b>."
+	OverrideCovererAway = "%s is away from %s to %s (reason: %s) and can't cover this time window."
+
+	InvalidAwayCommand          = "Invalid away command. Usage: !away <from: yyyy-mm-ddTHH:MM> <to: yyyy-mm-ddTHH:MM> [reason] | !away list | !away delete <id>"
+	InvalidAwayCommandWithError = "Invalid away command (%s)"
+
+	SettingItem                = "<li><b>%s</b>: %s (%s)</li>"
+	SettingList                = "Settings of this room: <ul>%s</ul>Change them with %s &lt;setting&gt; &lt;value&gt;."
+	SettingUpdated             = "Setting <b>%s</b> is %s now."
+	InvalidSetCommandWithError = "Invalid set command (%s). Usage: !set <setting> <value>"
+
+	HolidayItem                     = "<li>%s | %s (%s)</li>"
+	HolidayList                     = "Holidays of the next %d days: <ul>%s</ul>Import more by replying %s %s to an uploaded .ics file."
+	HolidaysImported                = "%d holidays imported from <b>%s</b>."
+	InvalidHolidaysCommandWithError = "Invalid holidays command (%s). Usage: !holidays [list] | !holidays import (as a reply to an .ics file)"
+
+	CalendarLink                    = "Subscribe to the shifts of this room in your calendar app with this link: %s<br>Anyone who has the link can see the shifts, so reset it with %s %s if it's leaked."
+	CalendarLinkOfUser              = "Subscribe to the shifts of %s in all the rooms in your calendar app with this link: %s<br>Anyone who has the link can see the shifts, so reset it with %s %s %s if it's leaked."
+	CalendarLinkReset               = "The previous link doesn't work anymore. "
+	CalendarLinkSent                = "%s, the link of your calendar feed is sent to you in a direct message."
+	InvalidCalendarCommandWithError = "Invalid calendar command (%s). Usage: !calendar <link|reset> [mine]"
+
+	TimeZoneOfUser                  = "The time zone of %s is <b>%s</b> (now it's %s). Change it with %s &lt;zone|local&gt;."
+	InvalidTimeZoneCommandWithError = "Invalid time zone command (%s). Usage: !timezone [zone|local]"
+
+	InvalidRotationCommand          = "Invalid rotation command. Usage: !rotation create [track=<name>] <name> <daily|weekly|duration> <HH:MM> <mentioned roster> | !rotation list | !rotation delete <id>"
+	InvalidRotationCommandWithError = "Invalid rotation command (%s)"
 )
@@ -0,0 +1,132 @@
+package matrix
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/matrix-org/gomatrix"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+type respResolveAlias struct {
+	RoomID string `json:"room_id"`
+}
+
+// onCall returns the active shifts of the room at the given time. The holders that are covered by someone else are
+// replaced by the covering people, like in the reports.
+func (b *Bot) onCall(roomID string, at time.Time) ([]model.Shift, error) {
+	active, err := b.shiftRepo.Active(roomID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting active shifts")
+	}
+
+	overrides, err := b.overrideRepo.Active(roomID, at)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting active overrides")
+	}
+
+	for i := range active {
+		for j := range active[i].Holders {
+			holder := &active[i].Holders[j]
+
+			for _, override := range overrides {
+				if override.Covers(holder.Holder, at, at) {
+					holder.Holder = override.Holder
+				}
+			}
+		}
+	}
+
+	return active, nil
+}
+
+// onCallCommand answers who is on call right now in the current room or in another room of the bot, which is given by
+// its id (!room:server) or alias (#room:server).
+func (b *Bot) onCallCommand(event *gomatrix.Event, parts []string) error {
+	roomID := event.RoomID
+	roomName := ""
+
+	if len(parts) > 1 {
+		roomName = parts[1]
+
+		var err error
+
+		roomID, err = b.resolveRoom(parts[1])
+		if err != nil {
+			return b.unknownRoom(event, parts[1])
+		}
+	}
+
+	if _, err := b.roomRepo.Find(roomID); errors.Is(err, gorm.ErrRecordNotFound) {
+		return b.unknownRoom(event, roomName)
+	} else if err != nil {
+		return errors.Wrap(err, "error getting room")
+	}
+
+	shifts, err := b.onCall(roomID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
+	if roomName == "" {
+		roomName = "this room"
+	}
+
+	message := fmt.Sprintf(NobodyOnCall, roomName)
+
+	if len(shifts) > 0 {
+		items := ""
+
+		for _, shift := range shifts {
+			for _, item := range shift.Holders {
+				holder, err := b.mention(item.Holder)
+				if err != nil {
+					return err
+				}
+
+				items += fmt.Sprintf(OnCallItem, holder, item.Tier, trackName(shift.Track),
+					formatTime(shift.StartTime, loc))
+			}
+		}
+
+		message = fmt.Sprintf(OnCallList, roomName, items)
+	}
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending on call message")
+	}
+
+	return nil
+}
+
+// resolveRoom returns the id of a room given by its id or alias.
+func (b *Bot) resolveRoom(room string) (string, error) {
+	if !strings.HasPrefix(room, "#") {
+		return room, nil
+	}
+
+	var resp respResolveAlias
+
+	if err := b.cli.MakeRequest("GET", b.cli.BuildURL("directory", "room", room), nil, &resp); err != nil {
+		return "", errors.Wrap(err, "error resolving room alias")
+	}
+
+	return resp.RoomID, nil
+}
+
+func (b *Bot) unknownRoom(event *gomatrix.Event, room string) error {
+	if _, err := b.cli.SendText(event.RoomID, fmt.Sprintf(UnknownRoom, room)); err != nil {
+		return errors.Wrap(err, "error sending unknown room message")
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package matrix
+
+import (
+	"bytes"
+	"html/template"
+	"time"
+
+	"github.com/matrix-org/gomatrix"
+	"github.com/pkg/errors"
+)
+
+const payReport = "pay"
+
+type PayReportTemplate struct {
+	Items []PayReportItemTemplate
+	From  string
+	To    string
+	Rates string
+	Total string
+}
+
+type PayReportItemTemplate struct {
+	HolderID      template.HTML
+	Amount        string
+	BusinessHours string
+	OffHours      string
+	WeekendHours  string
+	HolidayHours  string
+}
+
+// payReport sends the pay of each holder of the room between from and to by the rates of the room.
+func (b *Bot) payReport(event *gomatrix.Event, from, to time.Time, loc *time.Location) error {
+	payroll, err := b.calculator.Payroll(event.RoomID, from, to, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "error calculating pays")
+	}
+
+	items := make([]PayReportItemTemplate, 0, len(payroll.Pays))
+
+	for _, pay := range payroll.Pays {
+		displayName, err := b.cli.GetDisplayName(pay.Holder)
+		if err != nil {
+			return errors.Wrap(err, "error getting the display name of the holder")
+		}
+
+		items = append(items, PayReportItemTemplate{
+			HolderID:      template.HTML(b.mentionedText(pay.Holder, displayName.DisplayName)), //nolint:gosec
+			Amount:        payroll.Rates.Format(pay.Amount),
+			BusinessHours: formatHours(pay.Coverage.BusinessHours),
+			OffHours:      formatHours(pay.Coverage.OffHours),
+			WeekendHours:  formatHours(pay.Coverage.WeekendHours),
+			HolidayHours:  formatHours(pay.Coverage.HolidayHours),
+		})
+	}
+
+	var buf bytes.Buffer
+
+	if err := payTemplate.Execute(&buf, PayReportTemplate{
+		Items: items,
+		From:  formatTime(from, loc),
+		To:    formatTime(to, loc),
+		Rates: payroll.Rates.String(),
+		Total: payroll.Rates.Format(payroll.Total()),
+	}); err != nil {
+		return errors.Wrap(err, "error executing the pay report template")
+	}
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "pay report", buf.String()); err != nil {
+		return errors.Wrap(err, "error sending pay report")
+	}
+
+	return nil
+}
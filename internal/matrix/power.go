@@ -0,0 +1,74 @@
+package matrix
+
+import (
+	"fmt"
+
+	"github.com/matrix-org/gomatrix"
+	"github.com/pkg/errors"
+)
+
+const (
+	PowerLevelsEvent = "m.room.power_levels"
+
+	// defaultStateLevel is the level that is needed to send state events when the room does not set it.
+	defaultStateLevel = 50
+)
+
+// powerLevels is the content of the power levels state event of a room.
+type powerLevels struct {
+	Users        map[string]int `json:"users"`
+	UsersDefault int            `json:"users_default"`
+	Events       map[string]int `json:"events"`
+	StateDefault *int           `json:"state_default"`
+}
+
+// adminLevel is the level that is needed to change the power levels of the room. The users with it are its admins.
+func (p powerLevels) adminLevel() int {
+	if level, ok := p.Events[PowerLevelsEvent]; ok {
+		return level
+	}
+
+	if p.StateDefault != nil {
+		return *p.StateDefault
+	}
+
+	return defaultStateLevel
+}
+
+func (p powerLevels) level(userID string) int {
+	if level, ok := p.Users[userID]; ok {
+		return level
+	}
+
+	return p.UsersDefault
+}
+
+// isAdmin reports whether the user is an admin of the room. It also returns the admin level of the room.
+func (b *Bot) isAdmin(roomID, userID string) (bool, int, error) {
+	var levels powerLevels
+
+	if err := b.cli.StateEvent(roomID, PowerLevelsEvent, "", &levels); err != nil {
+		return false, 0, errors.Wrap(err, "error getting power levels")
+	}
+
+	adminLevel := levels.adminLevel()
+
+	return levels.level(userID) >= adminLevel, adminLevel, nil
+}
+
+// requireAdmin tells the sender that only the admins of the room can do the action when they are not one of them. It
+// reports whether the sender is an admin.
+func (b *Bot) requireAdmin(event *gomatrix.Event, action string) (bool, error) {
+	admin, level, err := b.isAdmin(event.RoomID, event.Sender)
+	if err != nil {
+		return false, err
+	}
+
+	if !admin {
+		if _, err := b.cli.SendFormattedText(event.RoomID, "", fmt.Sprintf(NotAdmin, level, action)); err != nil {
+			return false, errors.Wrap(err, "error sending not admin message")
+		}
+	}
+
+	return admin, nil
+}
@@ -0,0 +1,111 @@
+package matrix
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+// remind mentions the holders of the shifts that start or end soon, as set by the reminder settings of their room. The
+// next holders of the rotations are reminded of the handoffs too.
+func (b *Bot) remind(now time.Time) error {
+	for _, kind := range []string{model.ReminderStart, model.ReminderEnd, model.ReminderOverdue} {
+		shifts, err := b.reminderRepo.Due(kind, now)
+		if err != nil {
+			return errors.Wrap(err, "error getting due reminders")
+		}
+
+		for i := range shifts {
+			shift := &shifts[i]
+
+			if err := b.reminderRepo.Create(&model.Reminder{ShiftID: shift.ID, Kind: kind}); err != nil {
+				return errors.Wrap(err, "error saving reminder")
+			}
+
+			holders, err := b.holdersText(shift.Holders)
+			if err != nil {
+				return err
+			}
+
+			message, err := b.reminderMessage(kind, shift, holders)
+			if err != nil {
+				return err
+			}
+
+			if _, err := b.cli.SendFormattedText(shift.RoomID, "", message); err != nil {
+				return errors.Wrap(err, "error sending reminder")
+			}
+		}
+	}
+
+	return b.remindHandoffs(now)
+}
+
+// remindHandoffs mentions the people who take the shifts of the rotations on their next handoffs. The next holder is
+// skipped like on the handoff if they are away.
+func (b *Bot) remindHandoffs(now time.Time) error {
+	rotations, err := b.reminderRepo.DueHandoffs(now)
+	if err != nil {
+		return errors.Wrap(err, "error getting due handoff reminders")
+	}
+
+	for i := range rotations {
+		rotation := &rotations[i]
+
+		if err := b.reminderRepo.HandoffReminded(rotation); err != nil {
+			return errors.Wrap(err, "error saving handoff reminder")
+		}
+
+		loc, err := b.roomLocation(rotation.RoomID)
+		if err != nil {
+			return err
+		}
+
+		holder, err := b.availableHolder(rotation, rotation.NextHolder(), rotation.NextHandoff,
+			advanceHandoff(rotation.NextHandoff, rotation.Period, loc))
+		if err != nil {
+			return err
+		}
+
+		mention, err := b.mention(holder)
+		if err != nil {
+			return err
+		}
+
+		message := fmt.Sprintf(RotationStartReminder, mention, rotation.Name, trackName(rotation.Track),
+			formatTime(rotation.NextHandoff, loc))
+
+		if _, err := b.cli.SendFormattedText(rotation.RoomID, "", message); err != nil {
+			return errors.Wrap(err, "error sending handoff reminder")
+		}
+	}
+
+	return nil
+}
+
+func (b *Bot) reminderMessage(kind string, shift *model.Shift, holders string) (string, error) {
+	loc, err := b.roomLocation(shift.RoomID)
+	if err != nil {
+		return "", err
+	}
+
+	switch kind {
+	case model.ReminderStart:
+		return fmt.Sprintf(ShiftStartReminder, holders, shift.ID, trackName(shift.Track),
+			formatTime(shift.StartTime, loc)), nil
+	case model.ReminderOverdue:
+		room, err := b.room(shift.RoomID)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf(OverdueShiftWarning, holders, shift.ID, formatDuration(room.MaxShiftDuration),
+			formatTime(shift.StartTime.Add(room.MaxShiftDuration), loc), EndShift, Handoff), nil
+	default:
+		return fmt.Sprintf(ShiftEndReminder, holders, shift.ID, trackName(shift.Track),
+			formatTime(*shift.PlannedEndTime, loc), CreateNote), nil
+	}
+}
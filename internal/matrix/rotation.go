@@ -0,0 +1,381 @@
+package matrix
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matrix-org/gomatrix"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+const (
+	createRotation = "create"
+	listRotation   = "list"
+	deleteRotation = "delete"
+
+	daily  = "daily"
+	weekly = "weekly"
+
+	minCreateRotationLength int = 5
+	minDeleteRotationLength int = 3
+)
+
+func (b *Bot) rotation(event *gomatrix.Event, parts []string) error {
+	if len(parts) < minRotationLength {
+		return b.invalidRotation(event)
+	}
+
+	switch parts[1] {
+	case createRotation:
+		return b.createRotation(event, parts)
+	case listRotation:
+		return b.listRotations(event)
+	case deleteRotation:
+		return b.deleteRotation(event, parts)
+	default:
+		return b.invalidRotation(event)
+	}
+}
+
+// createRotation handles !rotation create <name> <daily|weekly|duration> <HH:MM|yyyy-mm-ddTHH:MM> <mentioned roster>.
+func (b *Bot) createRotation(event *gomatrix.Event, parts []string) error {
+	track, _, parts := option(parts, trackOption)
+	roster := mentionsOf(event)
+
+	if len(parts) < minCreateRotationLength || len(roster) == 0 {
+		return b.invalidRotation(event)
+	}
+
+	period, err := parsePeriod(parts[3])
+	if err != nil {
+		return b.invalidRotationWithError(event, err)
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
+	handoff, err := firstHandoff(parts[4], time.Now(), loc)
+	if err != nil {
+		return b.invalidRotationWithError(event, err)
+	}
+
+	holders := make([]string, 0, len(roster))
+	for _, item := range roster {
+		holders = append(holders, item.ID)
+	}
+
+	rotation := model.Rotation{
+		RoomID:      event.RoomID,
+		Sender:      event.Sender,
+		Name:        parts[2],
+		Track:       track,
+		Roster:      strings.Join(holders, model.RosterSeparator),
+		Period:      period,
+		Position:    0,
+		NextHandoff: handoff,
+	}
+
+	if err := b.rotationRepo.Create(&rotation); err != nil {
+		return errors.Wrap(err, "error saving rotation")
+	}
+
+	message := fmt.Sprintf(RotationCreated, rotation.Name, rotation.ID,
+		formatTime(handoff, loc), roster[0].Link)
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending rotation created message")
+	}
+
+	return nil
+}
+
+func (b *Bot) listRotations(event *gomatrix.Event) error {
+	rotations, err := b.rotationRepo.Get(event.RoomID)
+	if err != nil {
+		return errors.Wrap(err, "error getting rotations")
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
+	message := ""
+
+	for _, item := range rotations {
+		roster := make([]string, 0, len(item.Holders()))
+
+		for _, holder := range item.Holders() {
+			mention, err := b.mention(holder)
+			if err != nil {
+				return err
+			}
+
+			roster = append(roster, mention)
+		}
+
+		next, err := b.mention(item.NextHolder())
+		if err != nil {
+			return err
+		}
+
+		message += fmt.Sprintf(RotationItem, item.ID, item.Name, trackName(item.Track), formatPeriod(item.Period),
+			strings.Join(roster, ", "), formatTime(item.NextHandoff, loc), next)
+	}
+
+	message = fmt.Sprintf(RotationList, message)
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending rotations list")
+	}
+
+	return nil
+}
+
+func (b *Bot) deleteRotation(event *gomatrix.Event, parts []string) error {
+	if len(parts) < minDeleteRotationLength {
+		return b.invalidRotation(event)
+	}
+
+	rotationID, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return b.invalidRotationWithError(event, err)
+	}
+
+	message := fmt.Sprintf(RotationDeleted, rotationID)
+
+	if err := b.rotationRepo.Delete(event.RoomID, rotationID); errors.Is(err, gorm.ErrRecordNotFound) {
+		message = fmt.Sprintf(RotationNotFound, rotationID)
+	} else if err != nil {
+		return errors.Wrap(err, "error deleting rotation")
+	}
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending rotation deleted message")
+	}
+
+	return nil
+}
+
+func (b *Bot) invalidRotation(event *gomatrix.Event) error {
+	if _, err := b.cli.SendText(event.RoomID, InvalidRotationCommand); err != nil {
+		return errors.Wrap(err, "error sending invalid rotation command message")
+	}
+
+	return nil
+}
+
+func (b *Bot) invalidRotationWithError(event *gomatrix.Event, err error) error {
+	if _, err := b.cli.SendText(event.RoomID, fmt.Sprintf(InvalidRotationCommandWithError, err.Error())); err != nil {
+		return errors.Wrap(err, "error sending invalid rotation command message")
+	}
+
+	return nil
+}
+
+// rotate hands off every rotation that has reached its handoff time.
+func (b *Bot) rotate(now time.Time) error {
+	rotations, err := b.rotationRepo.Due(now)
+	if err != nil {
+		return errors.Wrap(err, "error getting due rotations")
+	}
+
+	for i := range rotations {
+		if err := b.handoffRotation(&rotations[i], now); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error":    err.Error(),
+				"rotation": rotations[i].ID,
+			}).Error("error handing off rotation")
+		}
+	}
+
+	return nil
+}
+
+// handoffRotation ends the active shifts of the rotation track and starts a shift for the next person of the roster
+// until the next handoff. If the bot missed some handoffs (e.g. it was down), the roster is advanced to the person who
+// owns the current period.
+func (b *Bot) handoffRotation(rotation *model.Rotation, now time.Time) error {
+	loc, err := b.roomLocation(rotation.RoomID)
+	if err != nil {
+		return err
+	}
+
+	at := rotation.NextHandoff
+	holder := rotation.NextHolder()
+
+	for !rotation.NextHandoff.After(now) {
+		at = rotation.NextHandoff
+		holder = rotation.NextHolder()
+		rotation.Position = (rotation.Position + 1) % len(rotation.Holders())
+		rotation.NextHandoff = advanceHandoff(rotation.NextHandoff, rotation.Period, loc)
+	}
+
+	end := rotation.NextHandoff
+
+	available, err := b.availableHolder(rotation, holder, at, end)
+	if err != nil {
+		return err
+	}
+
+	if available != holder {
+		if err := b.announceAbsentHolder(rotation, holder, available); err != nil {
+			return err
+		}
+
+		holder = available
+	}
+
+	if err := b.rotationRepo.Handoff(rotation, &model.Shift{
+		RoomID:         rotation.RoomID,
+		Sender:         rotation.Sender,
+		Holders:        []model.ShiftHolder{{Holder: holder, Tier: model.TierPrimary}},
+		Track:          rotation.Track,
+		StartTime:      at,
+		EndTime:        nil,
+		PlannedEndTime: &end,
+	}); err != nil {
+		return errors.Wrap(err, "error handing off rotation")
+	}
+
+	current, err := b.mention(holder)
+	if err != nil {
+		return err
+	}
+
+	next, err := b.mention(rotation.NextHolder())
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf(RotationHandoff, rotation.Name, current,
+		formatTime(rotation.NextHandoff, loc), next)
+
+	if _, err := b.cli.SendFormattedText(rotation.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending rotation handoff message")
+	}
+
+	return nil
+}
+
+// availableHolder returns the given holder of the rotation, or the first person of the roster after them who is not
+// away between from and to. The given holder is returned if everyone is away.
+func (b *Bot) availableHolder(rotation *model.Rotation, holder string, from, to time.Time) (string, error) {
+	roster := rotation.Holders()
+
+	absentees, err := b.absentees(roster, from, to)
+	if err != nil {
+		return "", err
+	}
+
+	start := 0
+
+	for i, item := range roster {
+		if item == holder {
+			start = i
+
+			break
+		}
+	}
+
+	for i := range roster {
+		candidate := roster[(start+i)%len(roster)]
+
+		if _, away := absentees[candidate]; !away {
+			return candidate, nil
+		}
+	}
+
+	return holder, nil
+}
+
+func (b *Bot) announceAbsentHolder(rotation *model.Rotation, holder, available string) error {
+	absent, err := b.mention(holder)
+	if err != nil {
+		return err
+	}
+
+	replacement, err := b.mention(available)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf(RotationHolderAway, absent, replacement, rotation.Name)
+
+	if _, err := b.cli.SendFormattedText(rotation.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending rotation holder away message")
+	}
+
+	return nil
+}
+
+func parsePeriod(value string) (time.Duration, error) {
+	switch strings.ToLower(value) {
+	case daily:
+		return dayHours * time.Hour, nil
+	case weekly:
+		return weekDays * dayHours * time.Hour, nil
+	}
+
+	period, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid period")
+	}
+
+	if period <= 0 {
+		return 0, errors.New("period must be positive")
+	}
+
+	return period, nil
+}
+
+func formatPeriod(period time.Duration) string {
+	switch period {
+	case dayHours * time.Hour:
+		return daily
+	case weekDays * dayHours * time.Hour:
+		return weekly
+	default:
+		return period.String()
+	}
+}
+
+// advanceHandoff returns the handoff after the given one. The periods of whole days, like daily and weekly, keep the
+// clock time of the handoff in the time zone of the room when the clocks change.
+func advanceHandoff(handoff time.Time, period time.Duration, loc *time.Location) time.Time {
+	day := dayHours * time.Hour
+	if period%day != 0 {
+		return handoff.Add(period)
+	}
+
+	return handoff.In(loc).AddDate(0, 0, int(period/day))
+}
+
+// firstHandoff parses the handoff time of a rotation in the time zone. A clock time (HH:MM) means its next occurrence
+// after now.
+func firstHandoff(value string, now time.Time, loc *time.Location) (time.Time, error) {
+	if handoff, err := time.ParseInLocation(dateTimeLayout, value, loc); err == nil {
+		return handoff, nil
+	}
+
+	clock, err := time.ParseInLocation(clockLayout, value, loc)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "invalid handoff time")
+	}
+
+	now = now.In(loc)
+	handoff := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+	if !handoff.After(now) {
+		handoff = handoff.AddDate(0, 0, 1)
+	}
+
+	return handoff, nil
+}
@@ -0,0 +1,187 @@
+package matrix
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // Imported for the time zones of the tests
+)
+
+func TestParsePeriod(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+		err      bool
+	}{
+		{value: "daily", expected: 24 * time.Hour},
+		{value: "Weekly", expected: 7 * 24 * time.Hour},
+		{value: "90m", expected: 90 * time.Minute},
+		{value: "0", err: true},
+		{value: "-12h", err: true},
+		{value: "monthly", err: true},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.value, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parsePeriod(test.value)
+			if test.err {
+				if err == nil {
+					t.Errorf("expected an error, got %s", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestAdvanceHandoff(t *testing.T) {
+	t.Parallel()
+
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("error loading time zone: %s", err)
+	}
+
+	tests := []struct {
+		name     string
+		handoff  time.Time
+		period   time.Duration
+		expected time.Time
+	}{
+		{
+			name:     "daily",
+			handoff:  time.Date(2022, 10, 18, 9, 0, 0, 0, loc),
+			period:   24 * time.Hour,
+			expected: time.Date(2022, 10, 19, 9, 0, 0, 0, loc),
+		},
+		{
+			name:     "daily with the clocks going back",
+			handoff:  time.Date(2022, 10, 29, 9, 0, 0, 0, loc),
+			period:   24 * time.Hour,
+			expected: time.Date(2022, 10, 30, 9, 0, 0, 0, loc),
+		},
+		{
+			name:     "weekly with the clocks going forward",
+			handoff:  time.Date(2022, 3, 21, 9, 0, 0, 0, loc).UTC(),
+			period:   7 * 24 * time.Hour,
+			expected: time.Date(2022, 3, 28, 9, 0, 0, 0, loc),
+		},
+		{
+			name:     "hours with the clocks going back",
+			handoff:  time.Date(2022, 10, 30, 0, 0, 0, 0, loc),
+			period:   12 * time.Hour,
+			expected: time.Date(2022, 10, 30, 11, 0, 0, 0, loc),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := advanceHandoff(test.handoff, test.period, loc); !got.Equal(test.expected) {
+				t.Errorf("expected %s, got %s", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestFirstHandoff(t *testing.T) {
+	t.Parallel()
+
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("error loading time zone: %s", err)
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		now      time.Time
+		expected time.Time
+		err      bool
+	}{
+		{
+			name:     "date and time",
+			value:    "2022-10-20T09:00",
+			now:      time.Date(2022, 10, 18, 12, 0, 0, 0, loc),
+			expected: time.Date(2022, 10, 20, 9, 0, 0, 0, loc),
+		},
+		{
+			name:     "clock later today",
+			value:    "18:30",
+			now:      time.Date(2022, 10, 18, 12, 0, 0, 0, loc),
+			expected: time.Date(2022, 10, 18, 18, 30, 0, 0, loc),
+		},
+		{
+			name:     "clock earlier today",
+			value:    "09:00",
+			now:      time.Date(2022, 10, 18, 12, 0, 0, 0, loc),
+			expected: time.Date(2022, 10, 19, 9, 0, 0, 0, loc),
+		},
+		{
+			name:     "clock now",
+			value:    "12:00",
+			now:      time.Date(2022, 10, 18, 12, 0, 0, 0, loc),
+			expected: time.Date(2022, 10, 19, 12, 0, 0, 0, loc),
+		},
+		{
+			name:     "now in another time zone",
+			value:    "01:00",
+			now:      time.Date(2022, 10, 18, 22, 30, 0, 0, time.UTC),
+			expected: time.Date(2022, 10, 19, 1, 0, 0, 0, loc),
+		},
+		{
+			name:     "clocks going back",
+			value:    "09:00",
+			now:      time.Date(2022, 10, 29, 12, 0, 0, 0, loc),
+			expected: time.Date(2022, 10, 30, 9, 0, 0, 0, loc),
+		},
+		{
+			name:  "invalid",
+			value: "9am",
+			now:   time.Date(2022, 10, 18, 12, 0, 0, 0, loc),
+			err:   true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := firstHandoff(test.value, test.now, loc)
+			if test.err {
+				if err == nil {
+					t.Errorf("expected an error, got %s", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.Equal(test.expected) {
+				t.Errorf("expected %s, got %s", test.expected, got)
+			}
+		})
+	}
+}
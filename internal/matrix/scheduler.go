@@ -0,0 +1,167 @@
+package matrix
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+// Schedule runs the time based jobs of the bot (like rotation handoffs) every interval until the bot is stopped.
+func (b *Bot) Schedule(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				b.tick(now)
+			case <-b.stopSignal:
+				ticker.Stop()
+
+				return
+			}
+		}
+	}()
+}
+
+func (b *Bot) tick(now time.Time) {
+	if err := b.rotate(now); err != nil {
+		logrus.WithField("error", err.Error()).Error("error running rotations")
+	}
+
+	if err := b.startScheduledShifts(now); err != nil {
+		logrus.WithField("error", err.Error()).Error("error starting scheduled shifts")
+	}
+
+	if err := b.endScheduledShifts(now); err != nil {
+		logrus.WithField("error", err.Error()).Error("error ending scheduled shifts")
+	}
+
+	if err := b.remind(now); err != nil {
+		logrus.WithField("error", err.Error()).Error("error sending reminders")
+	}
+
+	if err := b.endOverdueShifts(now); err != nil {
+		logrus.WithField("error", err.Error()).Error("error ending overdue shifts")
+	}
+}
+
+// startScheduledShifts announces the scheduled shifts that have reached their start time.
+func (b *Bot) startScheduledShifts(now time.Time) error {
+	shifts, err := b.shiftRepo.Starting(now)
+	if err != nil {
+		return errors.Wrap(err, "error getting starting shifts")
+	}
+
+	for i := range shifts {
+		shift := &shifts[i]
+
+		if err := b.shiftRepo.Started(shift); err != nil {
+			return errors.Wrap(err, "error updating started shift")
+		}
+
+		holders, err := b.holdersText(shift.Holders)
+		if err != nil {
+			return err
+		}
+
+		loc, err := b.roomLocation(shift.RoomID)
+		if err != nil {
+			return err
+		}
+
+		end := "-"
+		if shift.PlannedEndTime != nil {
+			end = formatTime(*shift.PlannedEndTime, loc)
+		}
+
+		message := fmt.Sprintf(ScheduledShiftStarted, shift.ID, trackName(shift.Track), holders, end)
+
+		if _, err := b.cli.SendFormattedText(shift.RoomID, "", message); err != nil {
+			return errors.Wrap(err, "error sending scheduled shift started message")
+		}
+	}
+
+	return nil
+}
+
+// endScheduledShifts ends the shifts that have reached their planned end time.
+func (b *Bot) endScheduledShifts(now time.Time) error {
+	shifts, err := b.shiftRepo.Ending(now)
+	if err != nil {
+		return errors.Wrap(err, "error getting ending shifts")
+	}
+
+	for i := range shifts {
+		shift := &shifts[i]
+
+		if err := b.shiftRepo.Update(&model.Shift{
+			ID:      shift.ID,
+			EndTime: shift.PlannedEndTime,
+			EndedBy: model.EndedBySystem,
+		}); err != nil {
+			return errors.Wrap(err, "error ending scheduled shift")
+		}
+
+		holders, err := b.holdersText(shift.Holders)
+		if err != nil {
+			return err
+		}
+
+		message := fmt.Sprintf(ScheduledShiftEnded, shift.ID, holders)
+
+		if _, err := b.cli.SendFormattedText(shift.RoomID, "", message); err != nil {
+			return errors.Wrap(err, "error sending scheduled shift ended message")
+		}
+	}
+
+	return nil
+}
+
+// endOverdueShifts ends the shifts that passed the maximum shift duration of their room. They are ended at the end of
+// the maximum duration, so the reports don't count the time after it.
+func (b *Bot) endOverdueShifts(now time.Time) error {
+	shifts, err := b.shiftRepo.Overdue(now)
+	if err != nil {
+		return errors.Wrap(err, "error getting overdue shifts")
+	}
+
+	for i := range shifts {
+		shift := &shifts[i]
+
+		room, err := b.room(shift.RoomID)
+		if err != nil {
+			return err
+		}
+
+		end := shift.StartTime.Add(room.MaxShiftDuration)
+		shift.EndTime = &end
+		shift.EndedBy = model.EndedBySystem
+
+		if err := b.shiftRepo.Update(shift); err != nil {
+			return errors.Wrap(err, "error ending overdue shift")
+		}
+
+		loc, err := b.roomLocation(shift.RoomID)
+		if err != nil {
+			return err
+		}
+
+		summary, err := b.shiftSummary(shift, loc)
+		if err != nil {
+			return err
+		}
+
+		message := fmt.Sprintf(OverdueShiftEnded, shift.ID, formatDuration(room.MaxShiftDuration)) + summary
+
+		if _, err := b.cli.SendFormattedText(shift.RoomID, "", message); err != nil {
+			return errors.Wrap(err, "error sending overdue shift ended message")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,313 @@
+package matrix
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matrix-org/gomatrix"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/compensation"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+const (
+	minSetLength int = 3
+
+	// noWeekend is the value of the weekend setting for a room without holidays.
+	noWeekend = "none"
+	// defaultRates is the value of the rates setting for a room with the default rates of the configuration.
+	defaultRates = "default"
+	// adminSetting is added to the description of the settings that only the admins can change.
+	adminSetting = ", admins only"
+)
+
+// roomSetting is a per room setting that can be shown with !settings and changed with !set.
+type roomSetting struct {
+	description string
+	// admin is set for the settings that change the reports and the pays, which only the admins of the room can change.
+	admin bool
+	get   func(room *model.Room) string
+	set   func(room *model.Room, value string) error
+}
+
+//nolint:gochecknoglobals
+var roomSettings = map[string]roomSetting{
+	"start_reminder": {
+		description: "how long before a scheduled shift or a rotation handoff starts its holders are reminded, " +
+			"like 30m (0 disables it)",
+		get: func(room *model.Room) string {
+			return room.StartReminder.String()
+		},
+		set: func(room *model.Room, value string) error {
+			lead, err := parseSettingDuration(value)
+			room.StartReminder = lead
+
+			return err
+		},
+	},
+	"end_reminder": {
+		description: "how long before a shift ends its holders are reminded to prepare the handover, like 1h (0 disables it)",
+		get: func(room *model.Room) string {
+			return room.EndReminder.String()
+		},
+		set: func(room *model.Room, value string) error {
+			lead, err := parseSettingDuration(value)
+			room.EndReminder = lead
+
+			return err
+		},
+	},
+	"max_shift_duration": {
+		description: "how long a shift can be in progress before the bot ends it, like 24h (0 disables it)",
+		admin:       true,
+		get: func(room *model.Room) string {
+			return room.MaxShiftDuration.String()
+		},
+		set: func(room *model.Room, value string) error {
+			duration, err := parseSettingDuration(value)
+			room.MaxShiftDuration = duration
+
+			return err
+		},
+	},
+	"weekend": {
+		description: "the days of the week that the reports count as holidays, like saturday,sunday (none for no holiday)",
+		admin:       true,
+		get:         weekendText,
+		set: func(room *model.Room, value string) error {
+			weekend, err := parseWeekend(value)
+			room.Weekend = weekend
+
+			return err
+		},
+	},
+	"working_hours": {
+		description: "the business hours of the working days, like 09:00-17:00, " +
+			"the rest of them are off-hours in the reports",
+		admin: true,
+		get: func(room *model.Room) string {
+			return room.WorkingHours
+		},
+		set: func(room *model.Room, value string) error {
+			if _, _, err := model.ParseWorkingHours(value); err != nil {
+				return err
+			}
+
+			room.WorkingHours = strings.ReplaceAll(value, " ", "")
+
+			return nil
+		},
+	},
+	"rates": {
+		description: "the hourly rates of the pays, like off_hours=10 weekend=20 holiday=30 currency=EUR " +
+			"(default for the config)",
+		admin: true,
+		get: func(room *model.Room) string {
+			if room.Rates == "" {
+				return defaultRates
+			}
+
+			return room.Rates
+		},
+		set: func(room *model.Room, value string) error {
+			if strings.EqualFold(value, defaultRates) {
+				room.Rates = ""
+
+				return nil
+			}
+
+			if _, err := compensation.ParseRates(value, compensation.Rates{}); err != nil {
+				return err
+			}
+
+			room.Rates = strings.Join(strings.Fields(value), compensation.RateSeparator)
+
+			return nil
+		},
+	},
+	"time_zone": {
+		description: "the time zone of the times in this room, like Asia/Tehran (local is the time zone of the server)",
+		admin:       true,
+		get: func(room *model.Room) string {
+			if room.TimeZone == "" {
+				return localTimeZone
+			}
+
+			return room.TimeZone
+		},
+		set: func(room *model.Room, value string) error {
+			if strings.EqualFold(value, localTimeZone) {
+				room.TimeZone = ""
+
+				return nil
+			}
+
+			loc, err := loadLocation(value)
+			if err != nil {
+				return err
+			}
+
+			room.TimeZone = loc.String()
+
+			return nil
+		},
+	},
+}
+
+// settings lists the settings of the room.
+func (b *Bot) settings(event *gomatrix.Event) error {
+	room, err := b.room(event.RoomID)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(roomSettings))
+	for key := range roomSettings {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	message := ""
+
+	for _, key := range keys {
+		description := roomSettings[key].description
+		if roomSettings[key].admin {
+			description += adminSetting
+		}
+
+		message += fmt.Sprintf(SettingItem, key, roomSettings[key].get(room), description)
+	}
+
+	message = fmt.Sprintf(SettingList, message, Set)
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending settings list")
+	}
+
+	return nil
+}
+
+// set changes a setting of the room. The settings of the reports and the pays can be changed only by the admins.
+func (b *Bot) set(event *gomatrix.Event, parts []string) error {
+	if len(parts) < minSetLength {
+		return b.invalidSetWithError(event, errors.New("missing setting or value"))
+	}
+
+	key := strings.ToLower(parts[1])
+
+	setting, ok := roomSettings[key]
+	if !ok {
+		return b.invalidSetWithError(event, errors.Errorf("unknown setting %s, list them with %s", parts[1], Settings))
+	}
+
+	if setting.admin {
+		admin, err := b.requireAdmin(event, "change "+key)
+		if err != nil || !admin {
+			return err
+		}
+	}
+
+	room, err := b.room(event.RoomID)
+	if err != nil {
+		return err
+	}
+
+	previous := setting.get(room)
+
+	if err := setting.set(room, strings.Join(parts[2:], " ")); err != nil {
+		return b.invalidSetWithError(event, err)
+	}
+
+	if err := b.roomRepo.UpdateSettings(room); err != nil {
+		return errors.Wrap(err, "error updating room settings")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"room_id":  event.RoomID,
+		"sender":   event.Sender,
+		"setting":  key,
+		"previous": previous,
+		"value":    setting.get(room),
+	}).Info("room setting changed")
+
+	message := fmt.Sprintf(SettingUpdated, key, setting.get(room))
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending setting updated message")
+	}
+
+	return nil
+}
+
+func (b *Bot) room(id string) (*model.Room, error) {
+	room, err := b.roomRepo.Find(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting room")
+	}
+
+	return room, nil
+}
+
+func (b *Bot) invalidSetWithError(event *gomatrix.Event, err error) error {
+	if _, err := b.cli.SendText(event.RoomID, fmt.Sprintf(InvalidSetCommandWithError, err.Error())); err != nil {
+		return errors.Wrap(err, "error sending invalid set command message")
+	}
+
+	return nil
+}
+
+func parseSettingDuration(value string) (time.Duration, error) {
+	if value == "0" {
+		return 0, nil
+	}
+
+	lead, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid duration")
+	}
+
+	if lead < 0 {
+		return 0, errors.New("duration can't be negative")
+	}
+
+	return lead, nil
+}
+
+// parseWeekend parses the comma separated days of a weekend (like: sat,sun) into the weekend of a room.
+func parseWeekend(value string) (string, error) {
+	if strings.EqualFold(value, noWeekend) {
+		return "", nil
+	}
+
+	days := make([]string, 0)
+
+	for _, name := range strings.Split(value, model.WeekendSeparator) {
+		day, ok := model.ParseWeekday(name)
+		if !ok {
+			return "", errors.Errorf("invalid day %s", name)
+		}
+
+		days = append(days, strings.ToLower(day.String()))
+	}
+
+	return strings.Join(days, model.WeekendSeparator), nil
+}
+
+func weekendText(room *model.Room) string {
+	days := room.WeekendDays()
+	if len(days) == 0 {
+		return noWeekend
+	}
+
+	names := make([]string, 0, len(days))
+	for _, day := range days {
+		names = append(names, strings.ToLower(day.String()))
+	}
+
+	return strings.Join(names, model.WeekendSeparator)
+}
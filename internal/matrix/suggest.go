@@ -0,0 +1,208 @@
+package matrix
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/matrix-org/gomatrix"
+	"github.com/pkg/errors"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/accounting"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+const (
+	daysOption         = "days"
+	defaultSuggestDays = 90
+
+	// holidayWeight is how many working days an on call holiday weighs in the suggestions.
+	holidayWeight = 2
+	// restDays is how many days since the last shift of a person make up for one working day in the suggestions.
+	restDays = 7
+)
+
+// suggestion is the load of a candidate for the next shift. The candidate with the lowest score is suggested.
+type suggestion struct {
+	Holder     string
+	WorkingDay int
+	Holiday    int
+	LastShift  *time.Time
+	Score      float64
+}
+
+// suggest proposes the next holder of the room (or the given track) based on the shifts of the last days. The people
+// who held shifts in that time are the candidates, unless some people are mentioned. People who are away are skipped.
+//
+//nolint:funlen,cyclop
+func (b *Bot) suggest(event *gomatrix.Event, parts []string) error {
+	track, filterTrack, parts := option(parts, trackOption)
+	daysValue, foundDays, _ := option(parts, daysOption)
+
+	days := defaultSuggestDays
+
+	if foundDays {
+		var err error
+
+		if days, err = strconv.Atoi(daysValue); err != nil || days < 1 {
+			if _, err := b.cli.SendText(event.RoomID, fmt.Sprintf(InvalidSuggestCommand, daysValue)); err != nil {
+				return errors.Wrap(err, "error sending invalid suggest command message")
+			}
+
+			return nil
+		}
+	}
+
+	now := time.Now()
+	from := now.AddDate(0, 0, -days)
+
+	shifts, err := b.shiftRepo.Report(event.RoomID, from, now)
+	if err != nil {
+		return errors.Wrap(err, "error in getting shifts from the db")
+	}
+
+	overrides, err := b.overrideRepo.Report(event.RoomID, from, now)
+	if err != nil {
+		return errors.Wrap(err, "error in getting overrides from the db")
+	}
+
+	shifts = model.ApplyOverrides(shifts, overrides)
+
+	if filterTrack {
+		shifts = model.InTrackReport(shifts, track)
+	}
+
+	mentioned := mentionsOf(event)
+	candidates := make(map[string]*suggestion)
+
+	for _, person := range mentioned {
+		candidates[person.ID] = &suggestion{Holder: person.ID}
+	}
+
+	room, err := b.room(event.RoomID)
+	if err != nil {
+		return err
+	}
+
+	calendar, err := b.calendar(room, from, now)
+	if err != nil {
+		return err
+	}
+
+	for key, item := range accounting.Tally(shifts, from, now, now, calendar) {
+		candidate, ok := candidates[key.Holder]
+		if !ok {
+			if len(mentioned) > 0 {
+				continue
+			}
+
+			candidate = &suggestion{Holder: key.Holder}
+			candidates[key.Holder] = candidate
+		}
+
+		candidate.WorkingDay += item.WorkingDays
+		candidate.Holiday += item.RestDays
+	}
+
+	for _, shift := range shifts {
+		candidate, ok := candidates[shift.Holders]
+		if !ok || shift.StartTime.After(now) {
+			continue
+		}
+
+		end := now
+		if shift.EndTime != nil && shift.EndTime.Before(now) {
+			end = *shift.EndTime
+		}
+
+		if candidate.LastShift == nil || candidate.LastShift.Before(end) {
+			candidate.LastShift = &end
+		}
+	}
+
+	ids := make([]string, 0, len(candidates))
+	for id := range candidates {
+		ids = append(ids, id)
+	}
+
+	// People who are away now can't take the next shift.
+	absentees, err := b.absentees(ids, now, now)
+	if err != nil {
+		return err
+	}
+
+	for id := range absentees {
+		delete(candidates, id)
+	}
+
+	if len(candidates) == 0 {
+		if _, err := b.cli.SendText(event.RoomID, fmt.Sprintf(NoSuggestion, days)); err != nil {
+			return errors.Wrap(err, "error sending no suggestion message")
+		}
+
+		return nil
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
+	ranked := rankSuggestions(candidates, now, days)
+	items := ""
+
+	for _, candidate := range ranked {
+		holder, err := b.mention(candidate.Holder)
+		if err != nil {
+			return err
+		}
+
+		last := "-"
+		if candidate.LastShift != nil {
+			last = formatTime(*candidate.LastShift, loc)
+		}
+
+		items += fmt.Sprintf(SuggestionItem, holder, candidate.WorkingDay, candidate.Holiday, last, candidate.Score)
+	}
+
+	next, err := b.mention(ranked[0].Holder)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf(SuggestionList, next, days, items)
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending suggestions")
+	}
+
+	return nil
+}
+
+// rankSuggestions scores the candidates and sorts them from the least loaded one. The score is the working days plus
+// the weighted holidays, minus a working day for every restDays since the last shift. People without a shift in the
+// last days are taken as rested for all of them.
+func rankSuggestions(candidates map[string]*suggestion, now time.Time, days int) []*suggestion {
+	res := make([]*suggestion, 0, len(candidates))
+
+	for _, candidate := range candidates {
+		rested := float64(days)
+		if candidate.LastShift != nil {
+			rested = now.Sub(*candidate.LastShift).Hours() / dayHours
+		}
+
+		candidate.Score = float64(candidate.WorkingDay) + holidayWeight*float64(candidate.Holiday) - rested/restDays
+		res = append(res, candidate)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score < res[j].Score
+		}
+
+		return res[i].Holder < res[j].Holder
+	})
+
+	return res
+}
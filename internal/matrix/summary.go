@@ -0,0 +1,127 @@
+package matrix
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+type ShiftSummaryTemplate struct {
+	ID int
+	// Holders and the senders of the notes are mention links, which are not escaped like the rest of the summary.
+	Holders    template.HTML
+	Track      string
+	Start      string
+	End        string
+	Duration   string
+	Categories []FollowUpCategoryTemplate
+	Notes      []NoteTemplate
+}
+
+type FollowUpCategoryTemplate struct {
+	Name  string
+	Open  int
+	Done  int
+	Items []model.FollowUp
+}
+
+type NoteTemplate struct {
+	Sender    template.HTML
+	Body      string
+	CreatedAt string
+}
+
+// shiftSummary renders the handover summary of an ended shift in the time zone. It contains the follow ups of the shift
+// grouped by their category and the notes of the shift.
+func (b *Bot) shiftSummary(shift *model.Shift, loc *time.Location) (string, error) {
+	followUps, err := b.followUpRepo.Get(shift.ID)
+	if err != nil {
+		return "", errors.Wrap(err, "error getting follow ups")
+	}
+
+	notes, err := b.noteRepo.Get(shift.ID)
+	if err != nil {
+		return "", errors.Wrap(err, "error getting notes")
+	}
+
+	holders, err := b.holdersText(shift.Holders)
+	if err != nil {
+		return "", err
+	}
+
+	end := time.Now()
+	if shift.EndTime != nil {
+		end = *shift.EndTime
+	}
+
+	tmp := ShiftSummaryTemplate{
+		ID:         shift.ID,
+		Holders:    template.HTML(holders), //nolint:gosec
+		Track:      trackName(shift.Track),
+		Start:      formatTime(shift.StartTime, loc),
+		End:        formatTime(end, loc),
+		Duration:   formatDuration(end.Sub(shift.StartTime)),
+		Categories: make([]FollowUpCategoryTemplate, 0),
+		Notes:      make([]NoteTemplate, 0, len(notes)),
+	}
+
+	for _, category := range []string{incoming, outgoing} {
+		item := FollowUpCategoryTemplate{Name: category, Open: 0, Done: 0, Items: make([]model.FollowUp, 0)}
+
+		for _, followUp := range followUps {
+			if followUp.Category != category {
+				continue
+			}
+
+			if followUp.Done {
+				item.Done++
+			} else {
+				item.Open++
+			}
+
+			item.Items = append(item.Items, followUp)
+		}
+
+		tmp.Categories = append(tmp.Categories, item)
+	}
+
+	for _, note := range notes {
+		sender, err := b.mention(note.Sender)
+		if err != nil {
+			return "", err
+		}
+
+		tmp.Notes = append(tmp.Notes, NoteTemplate{
+			Sender:    template.HTML(sender), //nolint:gosec
+			Body:      note.Body,
+			CreatedAt: formatTime(note.CreatedAt, loc),
+		})
+	}
+
+	var buf bytes.Buffer
+
+	if err := summaryTemplate.Execute(&buf, tmp); err != nil {
+		return "", errors.Wrap(err, "error in executing the summary template")
+	}
+
+	return buf.String(), nil
+}
+
+// formatDuration formats a duration in days, hours and minutes.
+func formatDuration(duration time.Duration) string {
+	duration = duration.Round(time.Minute)
+	days := duration / (dayHours * time.Hour)
+	hours := duration % (dayHours * time.Hour) / time.Hour
+	minutes := duration % time.Hour / time.Minute
+
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
@@ -0,0 +1,320 @@
+package matrix
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/matrix-org/gomatrix"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+const (
+	requestSwap = "request"
+	acceptSwap  = "accept"
+	declineSwap = "decline"
+	listSwap    = "list"
+
+	minRequestSwapLength int = 4
+	minResolveSwapLength int = 3
+)
+
+//nolint:gochecknoglobals
+var (
+	// acceptReactions are the reactions on a swap request which accept it.
+	acceptReactions = map[string]bool{"👍": true, "👍️": true, "✅": true, "✅️": true}
+	// declineReactions are the reactions on a swap request which decline it.
+	declineReactions = map[string]bool{"👎": true, "👎️": true, "❌": true, "❌️": true}
+)
+
+func (b *Bot) swap(event *gomatrix.Event, parts []string) error {
+	if len(parts) < minSwapLength {
+		return b.invalidSwap(event)
+	}
+
+	switch parts[1] {
+	case requestSwap:
+		return b.requestSwap(event, parts)
+	case acceptSwap, declineSwap:
+		if len(parts) < minResolveSwapLength {
+			return b.invalidSwap(event)
+		}
+
+		swapID, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return b.invalidSwap(event)
+		}
+
+		swap, err := b.swapRepo.Find(event.RoomID, swapID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if _, err := b.cli.SendFormattedText(event.RoomID, "", fmt.Sprintf(SwapNotFound, swapID)); err != nil {
+				return errors.Wrap(err, "error sending swap not found message")
+			}
+
+			return nil
+		} else if err != nil {
+			return errors.Wrap(err, "error getting swap")
+		}
+
+		return b.resolveSwap(event.RoomID, event.Sender, swap, parts[1] == acceptSwap)
+	case listSwap:
+		return b.listSwaps(event)
+	default:
+		return b.invalidSwap(event)
+	}
+}
+
+// requestSwap handles !swap request <shift id> <mentioned colleague>. The swapped holder is the sender, so nobody is
+// replaced in a shift without asking for it.
+//
+//nolint:funlen,cyclop
+func (b *Bot) requestSwap(event *gomatrix.Event, parts []string) error {
+	people := mentionsOf(event)
+
+	if len(parts) < minRequestSwapLength || len(people) == 0 {
+		return b.invalidSwap(event)
+	}
+
+	shiftID, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return b.invalidSwap(event)
+	}
+
+	shift, err := b.shiftRepo.Find(event.RoomID, shiftID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if _, err := b.cli.SendFormattedText(event.RoomID, "", fmt.Sprintf(ShiftNotFound, shiftID)); err != nil {
+			return errors.Wrap(err, "error sending shift not found message")
+		}
+
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "error getting shift")
+	}
+
+	if shift.EndTime != nil {
+		if _, err := b.cli.SendFormattedText(event.RoomID, "", fmt.Sprintf(SwapShiftEnded, shiftID)); err != nil {
+			return errors.Wrap(err, "error sending swap shift ended message")
+		}
+
+		return nil
+	}
+
+	target := people[0]
+	holderID := event.Sender
+
+	if !shift.HeldBy(holderID) {
+		mention, err := b.mention(holderID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := b.cli.SendFormattedText(event.RoomID, "", fmt.Sprintf(SwapNotHolder, mention, shiftID)); err != nil {
+			return errors.Wrap(err, "error sending swap not holder message")
+		}
+
+		return nil
+	}
+
+	to := shift.StartTime
+	if shift.PlannedEndTime != nil {
+		to = *shift.PlannedEndTime
+	} else if now := time.Now(); now.After(to) {
+		to = now
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
+	absentees, err := b.absentees([]string{target.ID}, shift.StartTime, to)
+	if err != nil {
+		return err
+	}
+
+	if absence, away := absentees[target.ID]; away {
+		message := fmt.Sprintf(SwapTargetAway, target.Link, formatTime(absence.StartTime, loc),
+			formatTime(absence.EndTime, loc), absenceReason(absence, event.RoomID), shift.ID)
+
+		if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+			return errors.Wrap(err, "error sending swap target away message")
+		}
+
+		return nil
+	}
+
+	swap := model.Swap{
+		RoomID:    event.RoomID,
+		ShiftID:   shift.ID,
+		Requester: event.Sender,
+		Holder:    holderID,
+		Target:    target.ID,
+		Status:    model.SwapPending,
+	}
+
+	if err := b.swapRepo.Create(&swap); err != nil {
+		return errors.Wrap(err, "error saving swap")
+	}
+
+	requester, err := b.mention(event.Sender)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf(SwapRequested, requester, target.Link, shift.ID, formatTime(shift.StartTime, loc),
+		target.Link, swap.ID, swap.ID, swap.ID)
+
+	resp, err := b.cli.SendFormattedText(event.RoomID, "", message)
+	if err != nil {
+		return errors.Wrap(err, "error sending swap requested message")
+	}
+
+	swap.EventID = resp.EventID
+
+	if err := b.swapRepo.SetEvent(&swap); err != nil {
+		return errors.Wrap(err, "error saving swap request event")
+	}
+
+	return nil
+}
+
+// resolveSwap accepts or declines a pending swap. Only the target of the swap can resolve it.
+func (b *Bot) resolveSwap(roomID, sender string, swap *model.Swap, accept bool) error {
+	var message string
+
+	switch {
+	case swap.Status != model.SwapPending:
+		message = fmt.Sprintf(SwapNotPending, swap.ID, swap.Status)
+	case swap.Target != sender:
+		target, err := b.mention(swap.Target)
+		if err != nil {
+			return err
+		}
+
+		message = fmt.Sprintf(SwapNotTarget, target, swap.ID)
+	case !accept:
+		if err := b.swapRepo.Decline(swap, time.Now()); err != nil {
+			return errors.Wrap(err, "error declining swap")
+		}
+
+		target, err := b.mention(swap.Target)
+		if err != nil {
+			return err
+		}
+
+		message = fmt.Sprintf(SwapDeclined, swap.ID, target)
+	default:
+		err := b.swapRepo.Accept(swap, time.Now())
+		if errors.Is(err, model.ErrShiftEnded) {
+			message = fmt.Sprintf(SwapShiftEnded, swap.ShiftID)
+
+			break
+		} else if errors.Is(err, model.ErrShiftCancelled) {
+			message = fmt.Sprintf(SwapShiftCancelled, swap.ShiftID)
+
+			break
+		} else if errors.Is(err, model.ErrNotHolder) {
+			holder, err := b.mention(swap.Holder)
+			if err != nil {
+				return err
+			}
+
+			message = fmt.Sprintf(SwapNotHolder, holder, swap.ShiftID)
+
+			break
+		} else if err != nil {
+			return errors.Wrap(err, "error accepting swap")
+		}
+
+		target, err := b.mention(swap.Target)
+		if err != nil {
+			return err
+		}
+
+		shiftID := swap.ShiftID
+		if swap.NewShiftID != nil {
+			shiftID = *swap.NewShiftID
+		}
+
+		message = fmt.Sprintf(SwapAccepted, swap.ID, target, shiftID)
+	}
+
+	if _, err := b.cli.SendFormattedText(roomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending swap resolved message")
+	}
+
+	return nil
+}
+
+func (b *Bot) listSwaps(event *gomatrix.Event) error {
+	swaps, err := b.swapRepo.Get(event.RoomID)
+	if err != nil {
+		return errors.Wrap(err, "error getting swaps")
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
+	message := ""
+
+	for _, item := range swaps {
+		holder, err := b.mention(item.Holder)
+		if err != nil {
+			return err
+		}
+
+		target, err := b.mention(item.Target)
+		if err != nil {
+			return err
+		}
+
+		message += fmt.Sprintf(SwapItem, item.ID, item.ShiftID, holder, target, item.Status,
+			formatTime(item.CreatedAt, loc))
+	}
+
+	message = fmt.Sprintf(SwapList, message)
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending swaps list")
+	}
+
+	return nil
+}
+
+func (b *Bot) invalidSwap(event *gomatrix.Event) error {
+	if _, err := b.cli.SendText(event.RoomID, InvalidSwapCommand); err != nil {
+		return errors.Wrap(err, "error sending invalid swap command message")
+	}
+
+	return nil
+}
+
+// HandleReaction handles the reactions on the messages of the bot. Reacting to a swap request accepts or declines it.
+func (b *Bot) HandleReaction(event *gomatrix.Event) error {
+	relatesTo, ok := event.Content["m.relates_to"].(map[string]interface{})
+	if !ok {
+		return ErrInvalidBody
+	}
+
+	eventID, _ := relatesTo["event_id"].(string)
+	key, _ := relatesTo["key"].(string)
+
+	accept := acceptReactions[key]
+	if eventID == "" || (!accept && !declineReactions[key]) {
+		return nil
+	}
+
+	swap, err := b.swapRepo.FindByEvent(event.RoomID, eventID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "error getting swap")
+	}
+
+	return b.resolveSwap(event.RoomID, event.Sender, swap, accept)
+}
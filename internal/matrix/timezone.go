@@ -0,0 +1,122 @@
+package matrix
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/matrix-org/gomatrix"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+// localTimeZone is the value of the time zone settings that means the local time zone of the server (or the room for a
+// user).
+const localTimeZone = "local"
+
+// timeZone shows the time zone of the sender with !timezone, or changes it with !timezone <zone>.
+func (b *Bot) timeZone(event *gomatrix.Event, parts []string) error {
+	if len(parts) > 1 {
+		preference := model.Preference{UserID: event.Sender, TimeZone: ""}
+
+		if !strings.EqualFold(parts[1], localTimeZone) {
+			loc, err := loadLocation(parts[1])
+			if err != nil {
+				if _, err := b.cli.SendText(event.RoomID, fmt.Sprintf(InvalidTimeZoneCommandWithError, err.Error())); err != nil {
+					return errors.Wrap(err, "error sending invalid time zone command message")
+				}
+
+				return nil
+			}
+
+			preference.TimeZone = loc.String()
+		}
+
+		if err := b.preferenceRepo.Save(&preference); err != nil {
+			return errors.Wrap(err, "error saving preference")
+		}
+	}
+
+	loc, err := b.location(event.RoomID, event.Sender)
+	if err != nil {
+		return err
+	}
+
+	mention, err := b.mention(event.Sender)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf(TimeZoneOfUser, mention, zoneName(loc), formatTime(time.Now(), loc), TimeZone)
+
+	if _, err := b.cli.SendFormattedText(event.RoomID, "", message); err != nil {
+		return errors.Wrap(err, "error sending time zone message")
+	}
+
+	return nil
+}
+
+// location returns the time zone that the times of the commands of the user in the room are parsed and rendered in. It
+// is the time zone of the user, or the time zone of the room if the user has not set one.
+func (b *Bot) location(roomID, userID string) (*time.Location, error) {
+	preference, err := b.preferenceRepo.Find(userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrap(err, "error getting preference")
+	}
+
+	if err == nil && preference.TimeZone != "" {
+		return loadLocation(preference.TimeZone)
+	}
+
+	return b.roomLocation(roomID)
+}
+
+// roomLocation returns the time zone of the room, or the local time zone of the server if the room has not set one. It
+// is used for the messages that are not an answer to someone, like the reminders.
+func (b *Bot) roomLocation(roomID string) (*time.Location, error) {
+	room, err := b.room(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := room.Location()
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting room time zone")
+	}
+
+	return loc, nil
+}
+
+func loadLocation(name string) (*time.Location, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "" {
+		return nil, errors.Errorf("unknown time zone %s, use an IANA name like Asia/Tehran", name)
+	}
+
+	return loc, nil
+}
+
+// formatTime renders the time in the time zone. The name of the zone is added to the abbreviation of the layout, since
+// the abbreviation of some zones is only an offset (like +0330).
+func formatTime(at time.Time, loc *time.Location) string {
+	res := at.In(loc).Format(time.RFC850)
+
+	if loc != time.Local {
+		res += fmt.Sprintf(" (%s)", loc)
+	}
+
+	return res
+}
+
+// zoneName returns the IANA name of the time zone, or the abbreviation of the local time zone of the server.
+func zoneName(loc *time.Location) string {
+	if loc == time.Local {
+		name, _ := time.Now().Zone()
+
+		return name
+	}
+
+	return loc.String()
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Absence is a time window that a person is not available for on call shifts. It is recorded in a room, but it applies
+// to the shifts of all the rooms.
+type Absence struct {
+	ID        int
+	RoomID    string
+	Holder    string
+	StartTime time.Time
+	EndTime   time.Time
+	Reason    string
+	CreatedAt time.Time
+}
+
+type AbsenceRepo interface {
+	Create(a *Absence) error
+	Get(holders []string, now time.Time) ([]Absence, error)
+	Delete(holder string, id int) error
+	Overlapping(holders []string, from time.Time, to time.Time) ([]Absence, error)
+}
+
+type SQLAbsenceRepo struct {
+	DB *gorm.DB
+}
+
+func (sa *SQLAbsenceRepo) Create(a *Absence) error {
+	return sa.DB.Create(a).Error
+}
+
+// Get returns the current and upcoming absences of the given people, in whichever room they are recorded.
+func (sa *SQLAbsenceRepo) Get(holders []string, now time.Time) ([]Absence, error) {
+	var res []Absence
+
+	if len(holders) == 0 {
+		return res, nil
+	}
+
+	err := sa.DB.Where("holder IN ? AND end_time > ?", holders, now).Order("start_time ASC").Find(&res).Error
+
+	return res, err
+}
+
+// Delete deletes an absence of the holder, in whichever room it is recorded. It returns gorm.ErrRecordNotFound if there
+// is no such absence.
+func (sa *SQLAbsenceRepo) Delete(holder string, id int) error {
+	res := sa.DB.Where("holder = ?", holder).Delete(&Absence{ID: id})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Overlapping returns the absences of the given people which overlap the time window between from and to. A window
+// with the same from and to is a moment.
+// nolint: varnamelen
+func (sa *SQLAbsenceRepo) Overlapping(holders []string, from time.Time, to time.Time) ([]Absence, error) {
+	var res []Absence
+
+	if len(holders) == 0 {
+		return res, nil
+	}
+
+	err := sa.DB.Where("holder IN ? AND start_time <= ? AND end_time > ?", holders, to, from).
+		Order("start_time ASC").
+		Find(&res).
+		Error
+
+	return res, err
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// calendarTokenBytes is the number of the random bytes of a calendar token.
+const calendarTokenBytes = 32
+
+// CalendarToken is the secret of the URL of a calendar feed. The feed of a room has no user and the feed of a user has
+// no room, since it has the shifts of the user in all the rooms.
+type CalendarToken struct {
+	Token     string `gorm:"primaryKey"`
+	RoomID    string
+	UserID    string
+	CreatedAt time.Time
+}
+
+// NewCalendarToken returns a calendar token with a new random secret.
+func NewCalendarToken(roomID, userID string) (*CalendarToken, error) {
+	secret := make([]byte, calendarTokenBytes)
+
+	if _, err := rand.Read(secret); err != nil {
+		return nil, errors.Wrap(err, "error generating calendar token")
+	}
+
+	return &CalendarToken{
+		Token:     hex.EncodeToString(secret),
+		RoomID:    roomID,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+type CalendarTokenRepo interface {
+	Find(token string) (*CalendarToken, error)
+	Get(roomID, userID string) (*CalendarToken, error)
+	Save(t *CalendarToken) error
+}
+
+type SQLCalendarTokenRepo struct {
+	DB *gorm.DB
+}
+
+func (sc *SQLCalendarTokenRepo) Find(token string) (*CalendarToken, error) {
+	var res CalendarToken
+
+	err := sc.DB.Where("token = ?", token).First(&res).Error
+
+	return &res, err
+}
+
+// Get returns the token of the feed of the room or the user.
+func (sc *SQLCalendarTokenRepo) Get(roomID, userID string) (*CalendarToken, error) {
+	var res CalendarToken
+
+	err := sc.DB.Where("room_id = ? AND user_id = ?", roomID, userID).First(&res).Error
+
+	return &res, err
+}
+
+// Save creates the token of the feed or replaces the previous one, so the old URL of the feed stops working.
+func (sc *SQLCalendarTokenRepo) Save(t *CalendarToken) error {
+	return sc.DB.Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"token", "created_at"})}).
+		Create(t).Error
+}
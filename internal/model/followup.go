@@ -15,6 +15,7 @@ type FollowUp struct {
 	Done        bool
 	Category    string
 	CreatedAt   time.Time
+	CancelledAt *time.Time
 }
 
 func (f FollowUp) TableName() string {
@@ -38,7 +39,7 @@ func (fu *SQLFollowUpRepo) Create(f *FollowUp) error {
 func (fu *SQLFollowUpRepo) Get(shiftID int) ([]FollowUp, error) {
 	var res []FollowUp
 
-	err := fu.DB.Where("shift_id = ?", shiftID).Find(&res).Error
+	err := fu.DB.Where("shift_id = ? AND cancelled_at IS NULL", shiftID).Find(&res).Error
 
 	return res, err
 }
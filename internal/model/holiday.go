@@ -0,0 +1,80 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/ical"
+)
+
+const (
+	// OrganizationHolidays is the room id of the holidays that apply to all the rooms.
+	OrganizationHolidays = ""
+	// DateLayout is the layout of the date of a holiday.
+	DateLayout = "2006-01-02"
+)
+
+// Holiday is a day (like a public holiday) that the reports count as a holiday besides the weekend.
+type Holiday struct {
+	ID     int
+	RoomID string
+	// Date is the start of the day in UTC, since the day itself is stored without a time zone.
+	Date      time.Time
+	Name      string
+	CreatedAt time.Time
+}
+
+type HolidayRepo interface {
+	Save(holidays []Holiday) error
+	Get(roomID string, from, to time.Time) ([]Holiday, error)
+}
+
+type SQLHolidayRepo struct {
+	DB *gorm.DB
+}
+
+// Save creates the holidays. The name of a holiday that already exists on the same day is replaced.
+func (sh *SQLHolidayRepo) Save(holidays []Holiday) error {
+	if len(holidays) == 0 {
+		return nil
+	}
+
+	return sh.DB.Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"name"})}).
+		Create(&holidays).Error
+}
+
+// Get returns the holidays of the room and of the organization between the from and to days.
+func (sh *SQLHolidayRepo) Get(roomID string, from, to time.Time) ([]Holiday, error) {
+	var res []Holiday
+
+	err := sh.DB.
+		Where("room_id IN ? AND date BETWEEN ? AND ?",
+			[]string{roomID, OrganizationHolidays}, from.Format(DateLayout), to.Format(DateLayout)).
+		Order("date ASC").
+		Find(&res).Error
+
+	return res, err
+}
+
+// CalendarHolidays returns a holiday of the room for every day of the events of a calendar.
+func CalendarHolidays(roomID string, events []ical.Event) []Holiday {
+	res := make([]Holiday, 0, len(events))
+	seen := make(map[string]bool)
+
+	for _, event := range events {
+		for _, day := range event.Days() {
+			if seen[day.Format(DateLayout)] {
+				continue
+			}
+
+			seen[day.Format(DateLayout)] = true
+
+			date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+			res = append(res, Holiday{RoomID: roomID, Date: date, Name: event.Summary})
+		}
+	}
+
+	return res
+}
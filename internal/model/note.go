@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Note is a free text note that the on call people leave on a shift for the handover.
+type Note struct {
+	ID        int
+	ShiftID   int
+	Sender    string
+	Body      string
+	CreatedAt time.Time
+}
+
+type NoteRepo interface {
+	Create(n *Note) error
+	Get(shiftID int) ([]Note, error)
+}
+
+type SQLNoteRepo struct {
+	DB *gorm.DB
+}
+
+func (sn *SQLNoteRepo) Create(n *Note) error {
+	return sn.DB.Create(n).Error
+}
+
+func (sn *SQLNoteRepo) Get(shiftID int) ([]Note, error) {
+	var res []Note
+
+	err := sn.DB.Where("shift_id = ?", shiftID).Order("created_at ASC").Find(&res).Error
+
+	return res, err
+}
@@ -0,0 +1,116 @@
+package model
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Override is a time window of a shift that is covered by someone other than its holder.
+type Override struct {
+	ID        int
+	ShiftID   int
+	RoomID    string
+	Sender    string
+	Holder    string
+	Covered   string
+	StartTime time.Time
+	EndTime   time.Time
+	CreatedAt time.Time
+}
+
+type OverrideRepo interface {
+	Create(o *Override) error
+	Active(roomID string, at time.Time) ([]Override, error)
+	Report(roomID string, from time.Time, to time.Time) ([]Override, error)
+}
+
+type SQLOverrideRepo struct {
+	DB *gorm.DB
+}
+
+func (so *SQLOverrideRepo) Create(o *Override) error {
+	return so.DB.Create(o).Error
+}
+
+// Active returns the overrides of the room that are in effect at the given time.
+func (so *SQLOverrideRepo) Active(roomID string, at time.Time) ([]Override, error) {
+	var res []Override
+
+	err := so.DB.Where("room_id = ? AND start_time <= ? AND end_time > ?", roomID, at, at).Find(&res).Error
+
+	return res, err
+}
+
+// nolint: varnamelen
+func (so *SQLOverrideRepo) Report(roomID string, from time.Time, to time.Time) ([]Override, error) {
+	var res []Override
+
+	err := so.DB.Where("room_id = ? AND start_time < ? AND end_time > ?", roomID, to, from).
+		Order("start_time ASC").
+		Find(&res).
+		Error
+
+	return res, err
+}
+
+// Covers reports whether the override covers the holder at some time between from and to. Both of them are included,
+// so an override covers the holder at a time when it's called with that time as from and to.
+func (o Override) Covers(holder string, from, to time.Time) bool {
+	return o.Covered == holder && !o.StartTime.After(to) && o.EndTime.After(from)
+}
+
+// ApplyOverrides splits the reported shifts by their overrides, so every covered time window is credited to the
+// covering person instead of the holder of the shift. An override covers its person in all of their shifts that it
+// overlaps, not only in the shift that it is saved for.
+func ApplyOverrides(shifts []ShiftReport, overrides []Override) []ShiftReport {
+	sort.Slice(overrides, func(i, j int) bool {
+		return overrides[i].StartTime.Before(overrides[j].StartTime)
+	})
+
+	res := make([]ShiftReport, 0, len(shifts))
+
+	for _, shift := range shifts {
+		cursor := shift.StartTime
+
+		for _, override := range overrides {
+			end := override.EndTime
+			if shift.EndTime != nil && shift.EndTime.Before(end) {
+				end = *shift.EndTime
+			}
+
+			if !override.Covers(shift.Holders, cursor, end) {
+				continue
+			}
+
+			start := override.StartTime
+			if start.Before(cursor) {
+				start = cursor
+			}
+
+			if !start.Before(end) {
+				continue
+			}
+
+			if cursor.Before(start) {
+				res = append(res, ShiftReport{
+					ID: shift.ID, Holders: shift.Holders, Track: shift.Track, StartTime: cursor, EndTime: &start,
+				})
+			}
+
+			res = append(res, ShiftReport{
+				ID: shift.ID, Holders: override.Holder, Track: shift.Track, StartTime: start, EndTime: &end,
+			})
+			cursor = end
+		}
+
+		if shift.EndTime == nil || cursor.Before(*shift.EndTime) {
+			res = append(res, ShiftReport{
+				ID: shift.ID, Holders: shift.Holders, Track: shift.Track, StartTime: cursor, EndTime: shift.EndTime,
+			})
+		}
+	}
+
+	return res
+}
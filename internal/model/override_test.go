@@ -0,0 +1,184 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+// nolint: funlen
+func TestApplyOverrides(t *testing.T) {
+	t.Parallel()
+
+	at := func(value string) *time.Time {
+		res, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			t.Fatalf("invalid time %s: %s", value, err)
+		}
+
+		return &res
+	}
+
+	shift := func(id int, holder, start, end string) model.ShiftReport {
+		res := model.ShiftReport{ID: id, Holders: holder, Track: model.DefaultTrack, StartTime: *at(start), EndTime: nil}
+		if end != "" {
+			res.EndTime = at(end)
+		}
+
+		return res
+	}
+
+	override := func(shiftID int, holder, covered, start, end string) model.Override {
+		return model.Override{ShiftID: shiftID, Holder: holder, Covered: covered, StartTime: *at(start), EndTime: *at(end)}
+	}
+
+	const (
+		a = "@a:example.com"
+		b = "@b:example.com"
+		c = "@c:example.com"
+	)
+
+	tests := []struct {
+		name      string
+		shifts    []model.ShiftReport
+		overrides []model.Override
+		expected  []model.ShiftReport
+	}{
+		{
+			name:     "no overrides",
+			shifts:   []model.ShiftReport{shift(1, a, "2022-10-10 09:00", "2022-10-10 17:00")},
+			expected: []model.ShiftReport{shift(1, a, "2022-10-10 09:00", "2022-10-10 17:00")},
+		},
+		{
+			name:      "override in the middle",
+			shifts:    []model.ShiftReport{shift(1, a, "2022-10-10 09:00", "2022-10-10 17:00")},
+			overrides: []model.Override{override(1, b, a, "2022-10-10 12:00", "2022-10-10 13:00")},
+			expected: []model.ShiftReport{
+				shift(1, a, "2022-10-10 09:00", "2022-10-10 12:00"),
+				shift(1, b, "2022-10-10 12:00", "2022-10-10 13:00"),
+				shift(1, a, "2022-10-10 13:00", "2022-10-10 17:00"),
+			},
+		},
+		{
+			name: "override over two shifts",
+			shifts: []model.ShiftReport{
+				shift(1, a, "2022-10-10 09:00", "2022-10-10 17:00"),
+				shift(2, a, "2022-10-10 17:00", "2022-10-11 09:00"),
+			},
+			overrides: []model.Override{override(1, b, a, "2022-10-10 16:00", "2022-10-10 18:00")},
+			expected: []model.ShiftReport{
+				shift(1, a, "2022-10-10 09:00", "2022-10-10 16:00"),
+				shift(1, b, "2022-10-10 16:00", "2022-10-10 17:00"),
+				shift(2, b, "2022-10-10 17:00", "2022-10-10 18:00"),
+				shift(2, a, "2022-10-10 18:00", "2022-10-11 09:00"),
+			},
+		},
+		{
+			name:      "override of another person",
+			shifts:    []model.ShiftReport{shift(1, a, "2022-10-10 09:00", "2022-10-10 17:00")},
+			overrides: []model.Override{override(1, b, c, "2022-10-10 12:00", "2022-10-10 13:00")},
+			expected:  []model.ShiftReport{shift(1, a, "2022-10-10 09:00", "2022-10-10 17:00")},
+		},
+		{
+			name:      "ongoing shift",
+			shifts:    []model.ShiftReport{shift(1, a, "2022-10-10 09:00", "")},
+			overrides: []model.Override{override(1, b, a, "2022-10-10 12:00", "2022-10-10 13:00")},
+			expected: []model.ShiftReport{
+				shift(1, a, "2022-10-10 09:00", "2022-10-10 12:00"),
+				shift(1, b, "2022-10-10 12:00", "2022-10-10 13:00"),
+				shift(1, a, "2022-10-10 13:00", ""),
+			},
+		},
+		{
+			name:      "override to the end of the shift",
+			shifts:    []model.ShiftReport{shift(1, a, "2022-10-10 09:00", "2022-10-10 17:00")},
+			overrides: []model.Override{override(1, b, a, "2022-10-10 16:00", "2022-10-10 20:00")},
+			expected: []model.ShiftReport{
+				shift(1, a, "2022-10-10 09:00", "2022-10-10 16:00"),
+				shift(1, b, "2022-10-10 16:00", "2022-10-10 17:00"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := model.ApplyOverrides(test.shifts, test.overrides)
+
+			if len(got) != len(test.expected) {
+				t.Fatalf("expected %d shifts, got %+v", len(test.expected), got)
+			}
+
+			for i, expected := range test.expected {
+				if !equalReports(got[i], expected) {
+					t.Errorf("expected %+v at %d, got %+v", expected, i, got[i])
+				}
+			}
+		})
+	}
+}
+
+func equalReports(a, b model.ShiftReport) bool {
+	if a.ID != b.ID || a.Holders != b.Holders || a.Track != b.Track || !a.StartTime.Equal(b.StartTime) {
+		return false
+	}
+
+	if a.EndTime == nil || b.EndTime == nil {
+		return a.EndTime == nil && b.EndTime == nil
+	}
+
+	return a.EndTime.Equal(*b.EndTime)
+}
+
+func TestOverrideCovers(t *testing.T) {
+	t.Parallel()
+
+	at := func(value string) time.Time {
+		res, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			t.Fatalf("invalid time %s: %s", value, err)
+		}
+
+		return res
+	}
+
+	const (
+		a = "@a:example.com"
+		c = "@c:example.com"
+	)
+
+	override := model.Override{
+		ShiftID: 1, Holder: "@b:example.com", Covered: a,
+		StartTime: at("2022-10-10 12:00"), EndTime: at("2022-10-10 13:00"),
+	}
+
+	tests := []struct {
+		name     string
+		holder   string
+		from     string
+		to       string
+		expected bool
+	}{
+		{name: "at the start", holder: a, from: "2022-10-10 12:00", to: "2022-10-10 12:00", expected: true},
+		{name: "at the end", holder: a, from: "2022-10-10 13:00", to: "2022-10-10 13:00", expected: false},
+		{name: "window over it", holder: a, from: "2022-10-10 09:00", to: "2022-10-10 17:00", expected: true},
+		{name: "window before it", holder: a, from: "2022-10-10 09:00", to: "2022-10-10 11:00", expected: false},
+		{name: "another holder", holder: c, from: "2022-10-10 12:30", to: "2022-10-10 12:30", expected: false},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := override.Covers(test.holder, at(test.from), at(test.to)); got != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Preference is the personal settings of a user. It applies to all the rooms.
+type Preference struct {
+	UserID string `gorm:"primaryKey"`
+	// TimeZone is the IANA name of the time zone that the times are parsed and rendered in for the user. Empty means the
+	// time zone of the room.
+	TimeZone  string
+	UpdatedAt time.Time
+}
+
+type PreferenceRepo interface {
+	Find(userID string) (*Preference, error)
+	Save(p *Preference) error
+}
+
+type SQLPreferenceRepo struct {
+	DB *gorm.DB
+}
+
+func (sp *SQLPreferenceRepo) Find(userID string) (*Preference, error) {
+	var res Preference
+
+	err := sp.DB.Where("user_id = ?", userID).First(&res).Error
+
+	return &res, err
+}
+
+// Save creates the preference of the user or replaces it.
+func (sp *SQLPreferenceRepo) Save(p *Preference) error {
+	return sp.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(p).Error
+}
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ReminderStart = "start"
+	ReminderEnd   = "end"
+	// ReminderOverdue warns the holders of a shift that it is going to pass the maximum duration of its room.
+	ReminderOverdue = "overdue"
+)
+
+// Reminder records that the holders of a shift are reminded of its start or end, so they are reminded only once even
+// if the bot restarts.
+type Reminder struct {
+	ID        int
+	ShiftID   int
+	Kind      string
+	CreatedAt time.Time
+}
+
+type ReminderRepo interface {
+	Create(r *Reminder) error
+	Due(kind string, now time.Time) ([]Shift, error)
+	DueHandoffs(now time.Time) ([]Rotation, error)
+	HandoffReminded(r *Rotation) error
+}
+
+type SQLReminderRepo struct {
+	DB *gorm.DB
+}
+
+func (sr *SQLReminderRepo) Create(r *Reminder) error {
+	return sr.DB.Create(r).Error
+}
+
+// Due returns the shifts whose start (or planned end) is closer than the reminder lead time of their room, or that are
+// going to pass the maximum duration of their room in MaxDurationWarning, and their holders are not reminded yet.
+func (sr *SQLReminderRepo) Due(kind string, now time.Time) ([]Shift, error) {
+	var res []Shift
+
+	query := sr.DB.Model(&Shift{}).
+		Scopes(notCancelled, withHolders).
+		Select("shifts.*").
+		Joins("JOIN rooms ON rooms.id = shifts.room_id").
+		Where("NOT EXISTS (SELECT 1 FROM reminders WHERE reminders.shift_id = shifts.id AND reminders.kind = ?)", kind)
+
+	switch kind {
+	case ReminderStart:
+		query = query.Where("shifts.pending = ? AND rooms.start_reminder > 0", true).
+			Where("shifts.start_time > ?", now).
+			Where("shifts.start_time <= DATE_ADD(?, INTERVAL rooms.start_reminder DIV 1000 MICROSECOND)", now)
+	case ReminderOverdue:
+		query = query.Where("shifts.end_time IS NULL AND shifts.start_time <= ? AND rooms.max_shift_duration > 0", now).
+			Where("DATE_ADD(shifts.start_time, INTERVAL rooms.max_shift_duration DIV 1000 MICROSECOND) <= ?",
+				now.Add(MaxDurationWarning))
+	default:
+		query = query.Where("shifts.end_time IS NULL AND shifts.start_time <= ? AND rooms.end_reminder > 0", now).
+			Where("shifts.planned_end_time > ?", now).
+			Where("shifts.planned_end_time <= DATE_ADD(?, INTERVAL rooms.end_reminder DIV 1000 MICROSECOND)", now)
+	}
+
+	err := query.Order("shifts.id ASC").Find(&res).Error
+
+	return res, err
+}
+
+// DueHandoffs returns the rotations whose next handoff is closer than the start reminder lead time of their room, and
+// their next holder is not reminded of it yet. The shifts of the rotations are created on the handoffs, so they are not
+// returned by Due.
+func (sr *SQLReminderRepo) DueHandoffs(now time.Time) ([]Rotation, error) {
+	var res []Rotation
+
+	err := sr.DB.Model(&Rotation{}).
+		Select("rotations.*").
+		Joins("JOIN rooms ON rooms.id = rotations.room_id").
+		Where("rooms.start_reminder > 0 AND rotations.next_handoff > ?", now).
+		Where("rotations.next_handoff <= DATE_ADD(?, INTERVAL rooms.start_reminder DIV 1000 MICROSECOND)", now).
+		Where("rotations.reminded_handoff IS NULL OR rotations.reminded_handoff <> rotations.next_handoff").
+		Order("rotations.id ASC").
+		Find(&res).
+		Error
+
+	return res, err
+}
+
+// HandoffReminded records that the next holder of the rotation is reminded of its next handoff.
+func (sr *SQLReminderRepo) HandoffReminded(r *Rotation) error {
+	r.RemindedHandoff = &r.NextHandoff
+
+	return sr.DB.Model(r).Update("reminded_handoff", r.NextHandoff).Error
+}
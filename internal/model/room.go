@@ -1,19 +1,54 @@
 package model
 
 import (
+	"strings"
 	"time"
 
+	"github.com/pkg/errors"
 	"gorm.io/gorm"
 )
 
+const (
+	// WeekendSeparator separates the days of the weekend of a room.
+	WeekendSeparator = ","
+	// DefaultWeekend is the weekend of the rooms that have not set one.
+	DefaultWeekend = "thursday,friday"
+	// WorkingHoursSeparator separates the start and the end of the working hours of a room.
+	WorkingHoursSeparator = "-"
+	// DefaultWorkingHours is the working hours of the rooms that have not set them.
+	DefaultWorkingHours = "09:00-17:00"
+
+	clockLayout = "15:04"
+)
+
 type Room struct {
 	ID        string
 	Sender    string
 	CreatedAt time.Time
+	// StartReminder is how long before the start of a scheduled shift its holders are reminded. Zero disables it.
+	StartReminder time.Duration
+	// EndReminder is how long before the planned end of a shift its holders are reminded to prepare the handover.
+	// Zero disables it.
+	EndReminder time.Duration
+	// MaxShiftDuration is how long a shift can be in progress before the bot ends it. Zero disables it.
+	MaxShiftDuration time.Duration
+	// TimeZone is the IANA name of the time zone that the times are parsed and rendered in. Empty means the local time
+	// zone of the server.
+	TimeZone string
+	// Weekend is the weekly rest days of the room (like: saturday,sunday), which the reports count as holidays.
+	Weekend string `gorm:"default:thursday,friday"`
+	// WorkingHours is the business hours of the working days of the room (like: 09:00-17:00). The rest of the working
+	// days is off-hours.
+	WorkingHours string `gorm:"default:09:00-17:00"`
+	// Rates is the hourly rate rules of the on call time of the room (like: off_hours=10 weekend=20), which override
+	// the default rates of the configuration. Empty means the default rates.
+	Rates string
 }
 
 type RoomRepo interface {
 	Create(r *Room) error
+	Find(id string) (*Room, error)
+	UpdateSettings(r *Room) error
 }
 
 type SQLRoomRepo struct {
@@ -23,3 +58,99 @@ type SQLRoomRepo struct {
 func (sr *SQLRoomRepo) Create(r *Room) error {
 	return sr.DB.Create(r).Error
 }
+
+func (sr *SQLRoomRepo) Find(id string) (*Room, error) {
+	var res Room
+
+	err := sr.DB.Where("id = ?", id).First(&res).Error
+
+	return &res, err
+}
+
+// UpdateSettings saves the per room settings of the room.
+func (sr *SQLRoomRepo) UpdateSettings(r *Room) error {
+	return sr.DB.Model(&Room{ID: r.ID}).
+		Select("start_reminder", "end_reminder", "max_shift_duration", "time_zone", "weekend", "working_hours",
+			"rates").
+		Updates(r).Error
+}
+
+// WeekendDays returns the weekly rest days of the room.
+func (r *Room) WeekendDays() []time.Weekday {
+	res := make([]time.Weekday, 0)
+
+	for _, name := range strings.Split(r.Weekend, WeekendSeparator) {
+		if day, ok := ParseWeekday(name); ok {
+			res = append(res, day)
+		}
+	}
+
+	return res
+}
+
+// ParseWeekday parses the name of a day of the week (like: friday) or its first three letters (like: fri).
+func ParseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+
+		if name == full || name == full[:3] {
+			return day, true
+		}
+	}
+
+	return 0, false
+}
+
+// Location returns the time zone of the room, or the local time zone of the server if the room has not set one.
+func (r *Room) Location() (*time.Location, error) {
+	if r.TimeZone == "" {
+		return time.Local, nil
+	}
+
+	loc, err := time.LoadLocation(r.TimeZone)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid time zone of room")
+	}
+
+	return loc, nil
+}
+
+// WorkTime returns the start and the end of the working hours of the room from the start of a day.
+func (r *Room) WorkTime() (time.Duration, time.Duration) {
+	start, end, err := ParseWorkingHours(r.WorkingHours)
+	if err != nil {
+		start, end, _ = ParseWorkingHours(DefaultWorkingHours)
+	}
+
+	return start, end
+}
+
+// ParseWorkingHours parses working hours like 09:00-17:00 into their start and end from the start of a day.
+func ParseWorkingHours(value string) (time.Duration, time.Duration, error) {
+	startValue, endValue, ok := strings.Cut(value, WorkingHoursSeparator)
+	if !ok {
+		return 0, 0, errors.Errorf("invalid working hours %s, use HH:MM-HH:MM", value)
+	}
+
+	start, err := time.Parse(clockLayout, strings.TrimSpace(startValue))
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "invalid start of working hours")
+	}
+
+	end, err := time.Parse(clockLayout, strings.TrimSpace(endValue))
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "invalid end of working hours")
+	}
+
+	if !start.Before(end) {
+		return 0, 0, errors.New("working hours must start before they end")
+	}
+
+	return clockOffset(start), clockOffset(end), nil
+}
+
+func clockOffset(clock time.Time) time.Duration {
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RosterSeparator separates the MXIDs of a rotation roster in the database.
+const RosterSeparator = ","
+
+type Rotation struct {
+	ID          int
+	RoomID      string
+	Sender      string
+	Name        string
+	Track       string
+	Roster      string
+	Period      time.Duration
+	Position    int
+	NextHandoff time.Time
+	// RemindedHandoff is the handoff that the next holder is reminded of, so they are reminded only once.
+	RemindedHandoff *time.Time
+	CreatedAt       time.Time
+}
+
+// Holders returns the roster of the rotation in handoff order.
+func (r Rotation) Holders() []string {
+	return strings.Split(r.Roster, RosterSeparator)
+}
+
+// NextHolder returns the MXID of the person who takes the shift on the next handoff.
+func (r Rotation) NextHolder() string {
+	holders := r.Holders()
+
+	return holders[r.Position%len(holders)]
+}
+
+type RotationRepo interface {
+	Create(r *Rotation) error
+	Get(roomID string) ([]Rotation, error)
+	Delete(roomID string, id int) error
+	Due(now time.Time) ([]Rotation, error)
+	Update(r *Rotation) error
+	Handoff(r *Rotation, s *Shift) error
+}
+
+type SQLRotationRepo struct {
+	DB *gorm.DB
+}
+
+func (sr *SQLRotationRepo) Create(r *Rotation) error {
+	return sr.DB.Create(r).Error
+}
+
+func (sr *SQLRotationRepo) Get(roomID string) ([]Rotation, error) {
+	var res []Rotation
+
+	err := sr.DB.Where("room_id = ?", roomID).Order("id ASC").Find(&res).Error
+
+	return res, err
+}
+
+func (sr *SQLRotationRepo) Delete(roomID string, id int) error {
+	res := sr.DB.Where("room_id = ?", roomID).Delete(&Rotation{ID: id})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Due returns the rotations whose next handoff has been reached.
+func (sr *SQLRotationRepo) Due(now time.Time) ([]Rotation, error) {
+	var res []Rotation
+
+	err := sr.DB.Where("next_handoff <= ?", now).Find(&res).Error
+
+	return res, err
+}
+
+func (sr *SQLRotationRepo) Update(r *Rotation) error {
+	return sr.DB.Model(r).Select("position", "next_handoff").Updates(r).Error
+}
+
+// Handoff hands the track of the rotation off to the given shift and saves the next handoff of the rotation in one
+// transaction, so the shift is not handed off twice if the rotation fails to be saved. The bot ends the active shifts
+// of the track on its own.
+func (sr *SQLRotationRepo) Handoff(r *Rotation, s *Shift) error {
+	if len(s.Holders) == 0 {
+		return ErrNoHolders
+	}
+
+	return sr.DB.Transaction(func(tx *gorm.DB) error {
+		if err := handoff(tx, s, EndedBySystem); err != nil {
+			return err
+		}
+
+		return tx.Model(r).Select("position", "next_handoff").Updates(r).Error
+	})
+}
@@ -1,58 +1,450 @@
 package model
 
 import (
+	"strings"
 	"time"
 
+	"github.com/pkg/errors"
 	"gorm.io/gorm"
 )
 
+const (
+	TierPrimary   = "primary"
+	TierSecondary = "secondary"
+	TierManager   = "manager"
+)
+
+var ErrNoHolders = errors.New("shift has no holders")
+
+// notCancelled excludes the cancelled shifts. They are kept in the table for auditing only.
+func notCancelled(db *gorm.DB) *gorm.DB {
+	return db.Where("cancelled_at IS NULL")
+}
+
+// Tiers is the list of escalation tiers in the order they should be paged.
+//
+//nolint:gochecknoglobals
+var Tiers = []string{TierPrimary, TierSecondary, TierManager}
+
+// IsTier reports whether the given value is the name of an escalation tier.
+func IsTier(value string) bool {
+	for _, tier := range Tiers {
+		if strings.EqualFold(tier, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DefaultTrack is the track of the shifts that are started without a track.
+const DefaultTrack = ""
+
+// EndedBySystem is recorded as the ender of the shifts that the bot ends on its own, like the scheduled shifts or the
+// shifts that passed the maximum duration of their room.
+const EndedBySystem = "system"
+
+// MaxDurationWarning is how long before a shift passes the maximum duration of its room its holders are warned.
+const MaxDurationWarning = time.Hour
+
+// byTier orders holders by their escalation tier, so the primary holders come first.
+const byTier = "FIELD(tier, 'primary', 'secondary', 'manager')"
+
+// withHolders loads the holders of the shifts in the order they should be paged.
+func withHolders(db *gorm.DB) *gorm.DB {
+	return db.Preload("Holders", func(db *gorm.DB) *gorm.DB {
+		return db.Order(byTier).Order("id ASC")
+	})
+}
+
 type Shift struct {
 	ID        int
 	RoomID    string
 	Sender    string
-	Holders   string
+	Holders   []ShiftHolder
+	Track     string
 	StartTime time.Time
 	EndTime   *time.Time
+	// PlannedEndTime is the time that the bot ends the shift at, if it is scheduled in advance.
+	PlannedEndTime *time.Time
+	// Pending is true for a scheduled shift until its start is announced.
+	Pending bool
+	// CancelledAt is set when the shift is cancelled, e.g. because it was started for the wrong people.
+	CancelledAt *time.Time
+	CancelledBy string
+	// EndedBy is the MXID of the person who ended the shift or EndedBySystem.
+	EndedBy string
+}
+
+// ShiftHolder is one of the people who hold a shift together with their escalation tier.
+type ShiftHolder struct {
+	ID      int
+	ShiftID int
+	Holder  string
+	Tier    string
+}
+
+// Upcoming reports whether the shift is scheduled to start after now.
+func (s Shift) Upcoming(now time.Time) bool {
+	return s.StartTime.After(now)
+}
+
+// HeldBy reports whether the given MXID is one of the holders of the shift.
+func (s Shift) HeldBy(id string) bool {
+	for _, holder := range s.Holders {
+		if holder.Holder == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HolderIDs returns the MXIDs of the holders of the shift.
+func (s Shift) HolderIDs() []string {
+	res := make([]string, 0, len(s.Holders))
+
+	for _, holder := range s.Holders {
+		res = append(res, holder.Holder)
+	}
+
+	return res
 }
 
 type ShiftRepo interface {
 	Create(s *Shift) error
+	CreateAll(shifts []Shift) error
 	Get(roomID string) ([]Shift, error)
 	Update(s *Shift) error
 	Active(RoomID string) ([]Shift, error)
 	Report(RoomID string, from time.Time, to time.Time) ([]ShiftReport, error)
+	Handoff(s *Shift) error
+	Overlapping(roomID string, from time.Time, to time.Time) ([]Shift, error)
+	Starting(now time.Time) ([]Shift, error)
+	Started(s *Shift) error
+	Ending(now time.Time) ([]Shift, error)
+	Overdue(now time.Time) ([]Shift, error)
+	Find(roomID string, id int) (*Shift, error)
+	Edit(s *Shift, edits []ShiftEdit) error
+	Cancel(s *Shift, sender string, at time.Time) error
+	List(roomID string, filter ShiftFilter, offset, limit int) ([]Shift, int64, error)
+	Feed(roomID string, filter ShiftFilter) ([]Shift, error)
+}
+
+// ShiftFilter narrows down the listed shifts. The zero value lists all the shifts.
+type ShiftFilter struct {
+	// Active lists only the shifts that are in progress.
+	Active bool
+	// Holder lists only the shifts that the given MXID holds.
+	Holder string
+	// Track lists only the shifts of the given track, if it is set.
+	Track *string
+	// From and To list only the shifts that are in progress at some point between them, if they are set.
+	From *time.Time
+	To   *time.Time
+}
+
+func (f ShiftFilter) apply(db *gorm.DB) *gorm.DB {
+	if f.Active {
+		db = db.Where("end_time IS NULL AND start_time <= ?", time.Now())
+	}
+
+	if f.Holder != "" {
+		db = db.Where("id IN (?)", db.Session(&gorm.Session{NewDB: true}).
+			Model(&ShiftHolder{}).
+			Select("shift_id").
+			Where("holder = ?", f.Holder))
+	}
+
+	if f.Track != nil {
+		db = db.Where("track = ?", *f.Track)
+	}
+
+	if f.From != nil {
+		db = db.Where("end_time IS NULL OR end_time >= ?", *f.From)
+	}
+
+	if f.To != nil {
+		db = db.Where("start_time < ?", *f.To)
+	}
+
+	return db
 }
 
 type SQLShiftRepo struct {
 	DB *gorm.DB
 }
 
+// Create saves the shift and its holders in one transaction.
 func (ss *SQLShiftRepo) Create(s *Shift) error {
+	if len(s.Holders) == 0 {
+		return ErrNoHolders
+	}
+
 	return ss.DB.Create(s).Error
 }
 
+// CreateAll saves the shifts and their holders in one transaction, so none of them is saved if one of them fails.
+func (ss *SQLShiftRepo) CreateAll(shifts []Shift) error {
+	return ss.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range shifts {
+			if len(shifts[i].Holders) == 0 {
+				return ErrNoHolders
+			}
+
+			if err := tx.Create(&shifts[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Update ends the shift for all of its holders, if it is not ended yet. An ended shift is not pending anymore, so its
+// start is not announced.
 func (ss *SQLShiftRepo) Update(s *Shift) error {
-	return ss.DB.Model(s).Where("end_time is null").Updates(&Shift{EndTime: s.EndTime}).Error
+	return ss.DB.Model(&Shift{ID: s.ID}).Where("end_time is null").Updates(map[string]interface{}{
+		"end_time": s.EndTime,
+		"ended_by": s.EndedBy,
+		"pending":  false,
+	}).Error
+}
+
+// Edit saves the start time, end time and holders of the shift and records the given edits in its history. A shift
+// without an end time is in progress again, so whoever ended it is cleared.
+func (ss *SQLShiftRepo) Edit(s *Shift, edits []ShiftEdit) error {
+	if len(s.Holders) == 0 {
+		return ErrNoHolders
+	}
+
+	values := map[string]interface{}{
+		"start_time": s.StartTime,
+		"end_time":   s.EndTime,
+	}
+
+	if s.EndTime == nil {
+		values["ended_by"] = ""
+	}
+
+	return ss.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Shift{ID: s.ID}).Updates(values).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("shift_id = ?", s.ID).Delete(&ShiftHolder{}).Error; err != nil {
+			return err
+		}
+
+		for i := range s.Holders {
+			s.Holders[i].ID = 0
+			s.Holders[i].ShiftID = s.ID
+		}
+
+		if err := tx.Create(&s.Holders).Error; err != nil {
+			return err
+		}
+
+		if len(edits) == 0 {
+			return nil
+		}
+
+		return tx.Create(&edits).Error
+	})
+}
+
+// Cancel soft deletes the shift and its follow ups, so they are not listed or reported anymore.
+func (ss *SQLShiftRepo) Cancel(s *Shift, sender string, at time.Time) error {
+	return ss.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Shift{ID: s.ID}).Updates(map[string]interface{}{
+			"cancelled_at": at,
+			"cancelled_by": sender,
+		}).Error; err != nil {
+			return err
+		}
+
+		s.CancelledAt = &at
+		s.CancelledBy = sender
+
+		return tx.Model(&FollowUp{}).Where("shift_id = ?", s.ID).Update("cancelled_at", at).Error
+	})
 }
 
 func (ss *SQLShiftRepo) Get(roomID string) ([]Shift, error) {
 	var res []Shift
 
-	err := ss.DB.Where("room_id = ?", roomID).Order("start_time ASC").Find(&res).Error
+	err := ss.DB.Scopes(notCancelled, withHolders).Where("room_id = ?", roomID).
+		Order("start_time ASC").
+		Order("track ASC").
+		Find(&res).
+		Error
+
+	return res, err
+}
+
+// List returns a page of the filtered shifts of the room, newest first, together with the total number of them.
+func (ss *SQLShiftRepo) List(roomID string, filter ShiftFilter, offset, limit int) ([]Shift, int64, error) {
+	var (
+		res   []Shift
+		total int64
+	)
+
+	inRoom := func(db *gorm.DB) *gorm.DB {
+		return db.Where("room_id = ?", roomID)
+	}
+
+	if err := ss.DB.Model(&Shift{}).Scopes(notCancelled, inRoom, filter.apply).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := ss.DB.Scopes(notCancelled, inRoom, filter.apply, withHolders).
+		Order("start_time DESC").
+		Order("id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&res).
+		Error
+
+	return res, total, err
+}
+
+// Feed returns the filtered shifts of the room, or of all the rooms if the room is empty, including the scheduled ones.
+func (ss *SQLShiftRepo) Feed(roomID string, filter ShiftFilter) ([]Shift, error) {
+	var res []Shift
+
+	db := ss.DB.Scopes(notCancelled, filter.apply, withHolders)
+	if roomID != "" {
+		db = db.Where("room_id = ?", roomID)
+	}
+
+	err := db.Order("start_time ASC").Order("id ASC").Find(&res).Error
 
 	return res, err
 }
 
+func (ss *SQLShiftRepo) Find(roomID string, id int) (*Shift, error) {
+	var res Shift
+
+	err := ss.DB.Scopes(notCancelled, withHolders).Where("room_id = ? AND id = ?", roomID, id).First(&res).Error
+
+	return &res, err
+}
+
 func (ss *SQLShiftRepo) Active(roomID string) ([]Shift, error) {
 	var res []Shift
 
-	err := ss.DB.Where("room_id = ? AND end_time is null", roomID).Find(&res).Error
+	err := ss.DB.Scopes(notCancelled, withHolders).
+		Where("room_id = ? AND end_time is null AND start_time <= ?", roomID, time.Now()).
+		Order("track ASC").
+		Find(&res).
+		Error
 
 	return res, err
 }
 
+// Handoff ends the active shifts of the room in the same track and starts the given shift at the same moment, which is
+// the start time of the shift. The active shifts are ended by the sender of the shift. Unresolved follow ups of the
+// track are moved to the new shift, so they are not lost on handovers.
+func (ss *SQLShiftRepo) Handoff(s *Shift) error {
+	if len(s.Holders) == 0 {
+		return ErrNoHolders
+	}
+
+	return ss.DB.Transaction(func(tx *gorm.DB) error {
+		return handoff(tx, s, s.Sender)
+	})
+}
+
+// handoff hands the track of the room off to the given shift in the transaction. The active shifts are recorded as
+// ended by endedBy.
+func handoff(tx *gorm.DB, s *Shift, endedBy string) error {
+	roomID := s.RoomID
+	track := s.Track
+	at := s.StartTime
+
+	if err := tx.Model(&Shift{}).Scopes(notCancelled).
+		Where("room_id = ? AND track = ? AND end_time is null AND start_time <= ?", roomID, track, at).
+		Updates(map[string]interface{}{"end_time": at, "ended_by": endedBy}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Create(s).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&FollowUp{}).
+		Where("done = ? AND cancelled_at IS NULL AND shift_id IN (?)", false,
+			tx.Model(&Shift{}).Select("id").Where("room_id = ? AND track = ?", roomID, track)).
+		Update("shift_id", s.ID).Error
+}
+
+// Overlapping returns the shifts of the room which are in progress at some point between from and to. The planned end
+// time of the shifts that are not ended yet is taken into account.
+// nolint: varnamelen
+func (ss *SQLShiftRepo) Overlapping(roomID string, from time.Time, to time.Time) ([]Shift, error) {
+	var res []Shift
+
+	err := ss.DB.Scopes(notCancelled, withHolders).Where("room_id = ? AND start_time < ?", roomID, to).
+		Where("COALESCE(end_time, planned_end_time) IS NULL OR COALESCE(end_time, planned_end_time) > ?", from).
+		Order("start_time ASC").
+		Find(&res).
+		Error
+
+	return res, err
+}
+
+// Starting returns the scheduled shifts which have reached their start time, but their start is not announced yet.
+func (ss *SQLShiftRepo) Starting(now time.Time) ([]Shift, error) {
+	var res []Shift
+
+	err := ss.DB.Scopes(notCancelled, withHolders).
+		Where("pending = ? AND end_time IS NULL AND start_time <= ?", true, now).
+		Order("start_time ASC").
+		Find(&res).
+		Error
+
+	return res, err
+}
+
+// Started marks a scheduled shift as announced.
+func (ss *SQLShiftRepo) Started(s *Shift) error {
+	return ss.DB.Model(&Shift{ID: s.ID}).Update("pending", false).Error
+}
+
+// Ending returns the shifts which have reached their planned end time, but are not ended yet.
+func (ss *SQLShiftRepo) Ending(now time.Time) ([]Shift, error) {
+	var res []Shift
+
+	err := ss.DB.Scopes(notCancelled, withHolders).Where("end_time is null AND planned_end_time <= ?", now).
+		Order("planned_end_time ASC").
+		Find(&res).
+		Error
+
+	return res, err
+}
+
+// Overdue returns the shifts in progress which have passed the maximum shift duration of their room.
+func (ss *SQLShiftRepo) Overdue(now time.Time) ([]Shift, error) {
+	var res []Shift
+
+	err := ss.DB.Model(&Shift{}).
+		Scopes(notCancelled, withHolders).
+		Select("shifts.*").
+		Joins("JOIN rooms ON rooms.id = shifts.room_id").
+		Where("shifts.end_time IS NULL AND rooms.max_shift_duration > 0").
+		Where("DATE_ADD(shifts.start_time, INTERVAL rooms.max_shift_duration DIV 1000 MICROSECOND) <= ?", now).
+		Order("shifts.id ASC").
+		Find(&res).
+		Error
+
+	return res, err
+}
+
+// ShiftReport is a shift of one of the holders. A shift with several holders is reported once for each of them.
 type ShiftReport struct {
+	ID        int
 	Holders   string
+	Track     string
 	StartTime time.Time
 	EndTime   *time.Time
 }
@@ -62,7 +454,9 @@ func (ss *SQLShiftRepo) Report(roomID string, from time.Time, to time.Time) ([]S
 	var res []ShiftReport
 
 	err := ss.DB.Table("shifts").
-		Select("holders", "start_time", "end_time").
+		Scopes(notCancelled).
+		Select("shifts.id", "shift_holders.holder AS holders", "track", "start_time", "end_time").
+		Joins("JOIN shift_holders ON shift_holders.shift_id = shifts.id").
 		Where("room_id", roomID).
 		Where("((start_time < ?) AND (end_time >= ?) AND (end_time <= ?) ) OR "+
 			"((start_time >= ?) AND (start_time <= ?) AND (end_time >= ?) AND (end_time <= ?) ) OR "+
@@ -78,3 +472,29 @@ func (ss *SQLShiftRepo) Report(roomID string, from time.Time, to time.Time) ([]S
 
 	return res, err
 }
+
+// InTrack returns the shifts that belong to the given track.
+func InTrack(shifts []Shift, track string) []Shift {
+	res := make([]Shift, 0, len(shifts))
+
+	for _, shift := range shifts {
+		if shift.Track == track {
+			res = append(res, shift)
+		}
+	}
+
+	return res
+}
+
+// InTrackReport returns the reported shifts that belong to the given track.
+func InTrackReport(shifts []ShiftReport, track string) []ShiftReport {
+	res := make([]ShiftReport, 0, len(shifts))
+
+	for _, shift := range shifts {
+		if shift.Track == track {
+			res = append(res, shift)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ShiftFieldStart   = "start"
+	ShiftFieldEnd     = "end"
+	ShiftFieldHolders = "holders"
+)
+
+// ShiftEdit is a change of a field of a shift after it is created. Times are stored in RFC3339 and an empty value means
+// the field was not set.
+type ShiftEdit struct {
+	ID        int
+	ShiftID   int
+	Sender    string
+	Field     string
+	OldValue  string
+	NewValue  string
+	CreatedAt time.Time
+}
+
+type ShiftEditRepo interface {
+	Get(shiftID int) ([]ShiftEdit, error)
+}
+
+type SQLShiftEditRepo struct {
+	DB *gorm.DB
+}
+
+func (se *SQLShiftEditRepo) Get(shiftID int) ([]ShiftEdit, error) {
+	var res []ShiftEdit
+
+	err := se.DB.Where("shift_id = ?", shiftID).Order("created_at ASC").Order("id ASC").Find(&res).Error
+
+	return res, err
+}
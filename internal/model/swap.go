@@ -0,0 +1,163 @@
+package model
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	SwapPending  = "pending"
+	SwapAccepted = "accepted"
+	SwapDeclined = "declined"
+)
+
+var (
+	ErrShiftEnded     = errors.New("shift is already ended")
+	ErrShiftCancelled = errors.New("shift is cancelled")
+	ErrNotHolder      = errors.New("holder of the swap does not hold the shift anymore")
+)
+
+// Swap is a request for handing over a shift to a colleague, which is applied only if the colleague accepts it.
+type Swap struct {
+	ID         int
+	RoomID     string
+	ShiftID    int
+	Requester  string
+	Holder     string
+	Target     string
+	Status     string
+	EventID    string
+	NewShiftID *int
+	CreatedAt  time.Time
+	ResolvedAt *time.Time
+}
+
+type SwapRepo interface {
+	Create(s *Swap) error
+	Get(roomID string) ([]Swap, error)
+	Find(roomID string, id int) (*Swap, error)
+	FindByEvent(roomID, eventID string) (*Swap, error)
+	SetEvent(s *Swap) error
+	Accept(s *Swap, at time.Time) error
+	Decline(s *Swap, at time.Time) error
+}
+
+type SQLSwapRepo struct {
+	DB *gorm.DB
+}
+
+func (sr *SQLSwapRepo) Create(s *Swap) error {
+	return sr.DB.Create(s).Error
+}
+
+func (sr *SQLSwapRepo) Get(roomID string) ([]Swap, error) {
+	var res []Swap
+
+	err := sr.DB.Where("room_id = ?", roomID).Order("id ASC").Find(&res).Error
+
+	return res, err
+}
+
+func (sr *SQLSwapRepo) Find(roomID string, id int) (*Swap, error) {
+	var res Swap
+
+	err := sr.DB.Where("room_id = ? AND id = ?", roomID, id).First(&res).Error
+
+	return &res, err
+}
+
+// FindByEvent returns the swap that is requested by the given event of the bot.
+func (sr *SQLSwapRepo) FindByEvent(roomID, eventID string) (*Swap, error) {
+	var res Swap
+
+	err := sr.DB.Where("room_id = ? AND event_id = ?", roomID, eventID).First(&res).Error
+
+	return &res, err
+}
+
+func (sr *SQLSwapRepo) SetEvent(s *Swap) error {
+	return sr.DB.Model(s).Update("event_id", s.EventID).Error
+}
+
+// Accept replaces the holder of the shift with the target of the swap. The holder of an upcoming shift is replaced in
+// place, but a shift in progress is ended and the rest of it is started as a new shift with the target instead of the
+// holder, so the reports show who actually worked. The shift is ended by the requester of the swap and the open follow
+// ups are moved to the new shift.
+//
+//nolint:funlen
+func (sr *SQLSwapRepo) Accept(s *Swap, at time.Time) error {
+	return sr.DB.Transaction(func(tx *gorm.DB) error {
+		var shift Shift
+
+		if err := tx.Scopes(withHolders).First(&shift, s.ShiftID).Error; err != nil {
+			return err
+		}
+
+		switch {
+		case shift.CancelledAt != nil:
+			return ErrShiftCancelled
+		case shift.EndTime != nil:
+			return ErrShiftEnded
+		case !shift.HeldBy(s.Holder):
+			return ErrNotHolder
+		case shift.StartTime.After(at):
+			if err := tx.Model(&ShiftHolder{}).
+				Where("shift_id = ? AND holder = ?", shift.ID, s.Holder).
+				Update("holder", s.Target).Error; err != nil {
+				return err
+			}
+		default:
+			if err := tx.Model(&Shift{ID: shift.ID}).
+				Updates(map[string]interface{}{"end_time": at, "ended_by": s.Requester}).Error; err != nil {
+				return err
+			}
+
+			holders := make([]ShiftHolder, 0, len(shift.Holders))
+
+			for _, holder := range shift.Holders {
+				if holder.Holder == s.Holder {
+					holder.Holder = s.Target
+				}
+
+				holders = append(holders, ShiftHolder{Holder: holder.Holder, Tier: holder.Tier})
+			}
+
+			next := Shift{
+				RoomID:         shift.RoomID,
+				Sender:         s.Requester,
+				Holders:        holders,
+				Track:          shift.Track,
+				StartTime:      at,
+				EndTime:        nil,
+				PlannedEndTime: shift.PlannedEndTime,
+				Pending:        false,
+			}
+
+			if err := tx.Create(&next).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Model(&FollowUp{}).
+				Where("shift_id = ? AND done = ?", shift.ID, false).
+				Update("shift_id", next.ID).Error; err != nil {
+				return err
+			}
+
+			s.NewShiftID = &next.ID
+		}
+
+		s.Status = SwapAccepted
+		s.ResolvedAt = &at
+
+		return tx.Model(s).Select("status", "resolved_at", "new_shift_id").Updates(s).Error
+	})
+}
+
+func (sr *SQLSwapRepo) Decline(s *Swap, at time.Time) error {
+	s.Status = SwapDeclined
+	s.ResolvedAt = &at
+
+	return sr.DB.Model(s).Select("status", "resolved_at").Updates(s).Error
+}
@@ -0,0 +1,322 @@
+// Package schedule imports a plan of shifts from a CSV or an iCalendar (.ics) file. The shifts of the file are
+// previewed with their conflicts first and only the valid ones are scheduled when the import is confirmed.
+package schedule
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/ical"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+)
+
+const (
+	csvExtension  = ".csv"
+	icalExtension = ".ics"
+
+	startColumn  = "start"
+	endColumn    = "end"
+	holderColumn = "holder"
+	tierColumn   = "tier"
+	trackColumn  = "track"
+
+	trackOption = "track="
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown file format, use a .csv or an .ics file")
+	ErrNoShifts      = errors.New("no shifts found")
+
+	// timeLayouts are the layouts of the times of a CSV file.
+	//
+	//nolint:gochecknoglobals
+	timeLayouts = []string{"2006-01-02T15:04", "2006-01-02 15:04", time.RFC3339}
+
+	// holderRegexp matches an MXID with its optional tier after it, like: @a:example.com (secondary).
+	holderRegexp = regexp.MustCompile(`(@[^\s:,;()]+:[^\s,;()]+)(?:\s*\((\w+)\))?`)
+	// mxidRegexp matches an MXID, like: @a:example.com.
+	mxidRegexp = regexp.MustCompile(`^@[^\s:]+:\S+$`)
+)
+
+// Parse returns the shifts of the file by its extension. The times without a time zone are in the given location.
+// The shifts are scheduled in the room by the sender.
+func Parse(name string, content []byte, roomID, sender string, loc *time.Location) ([]model.Shift, error) {
+	var (
+		res []model.Shift
+		err error
+	)
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case csvExtension:
+		res, err = parseCSV(bytes.NewReader(content), loc)
+	case icalExtension:
+		res, err = parseCalendar(bytes.NewReader(content), loc)
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, ErrNoShifts
+	}
+
+	for i := range res {
+		res[i].RoomID = roomID
+		res[i].Sender = sender
+		res[i].Pending = true
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].StartTime.Before(res[j].StartTime)
+	})
+
+	return res, nil
+}
+
+// parseCSV parses a CSV file with a header of start, end, holder and the optional tier and track columns. A holder
+// cell can have several MXIDs and the rows of the same time and track are one shift.
+//
+//nolint:cyclop
+func parseCSV(r io.Reader, loc *time.Location) ([]model.Shift, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ErrNoShifts
+	} else if err != nil {
+		return nil, errors.Wrap(err, "error reading csv")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range []string{startColumn, endColumn, holderColumn} {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.Errorf("missing %s column", name)
+		}
+	}
+
+	res := make([]model.Shift, 0)
+	index := make(map[string]int)
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "error reading csv")
+		}
+
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+
+			return ""
+		}
+
+		start, err := parseTime(cell(startColumn), loc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid start in line %d", line)
+		}
+
+		end, err := parseTime(cell(endColumn), loc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid end in line %d", line)
+		}
+
+		tier := strings.ToLower(cell(tierColumn))
+		if tier == "" {
+			tier = model.TierPrimary
+		} else if !model.IsTier(tier) {
+			return nil, errors.Errorf("invalid tier %s in line %d", tier, line)
+		}
+
+		holders := make([]model.ShiftHolder, 0)
+
+		for _, id := range strings.Fields(cell(holderColumn)) {
+			if !mxidRegexp.MatchString(id) {
+				return nil, errors.Errorf("invalid holder %s in line %d", id, line)
+			}
+
+			holders = append(holders, model.ShiftHolder{Holder: id, Tier: tier})
+		}
+
+		if len(holders) == 0 {
+			return nil, errors.Errorf("no holder in line %d", line)
+		}
+
+		track := cell(trackColumn)
+		key := fmt.Sprintf("%d-%d-%s", start.Unix(), end.Unix(), track)
+
+		if i, ok := index[key]; ok {
+			res[i].Holders = append(res[i].Holders, holders...)
+
+			continue
+		}
+
+		index[key] = len(res)
+		res = append(res, newShift(holders, track, start, end))
+	}
+
+	return res, nil
+}
+
+// parseCalendar parses the events of a calendar as shifts. The holders are the MXIDs in the summary or the description
+// of the events with their optional tier after them, like: @a:example.com (secondary). The track is set by track=name.
+func parseCalendar(r io.Reader, loc *time.Location) ([]model.Shift, error) {
+	events, err := ical.Parse(r, loc)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing calendar")
+	}
+
+	res := make([]model.Shift, 0, len(events))
+
+	for _, event := range events {
+		text := event.Summary + "\n" + event.Description
+
+		holders := make([]model.ShiftHolder, 0)
+
+		for _, match := range holderRegexp.FindAllStringSubmatch(text, -1) {
+			tier := strings.ToLower(match[2])
+			if !model.IsTier(tier) {
+				tier = model.TierPrimary
+			}
+
+			holders = append(holders, model.ShiftHolder{Holder: match[1], Tier: tier})
+		}
+
+		if len(holders) == 0 {
+			return nil, errors.Errorf("no holder in event %q", event.Summary)
+		}
+
+		track := model.DefaultTrack
+
+		for _, field := range strings.Fields(text) {
+			if strings.HasPrefix(strings.ToLower(field), trackOption) {
+				track = field[len(trackOption):]
+			}
+		}
+
+		res = append(res, newShift(holders, track, event.Start, event.End))
+	}
+
+	return res, nil
+}
+
+func newShift(holders []model.ShiftHolder, track string, start, end time.Time) model.Shift {
+	return model.Shift{
+		Holders:        holders,
+		Track:          track,
+		StartTime:      start,
+		EndTime:        nil,
+		PlannedEndTime: &end,
+	}
+}
+
+func parseTime(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if at, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return at, nil
+		}
+	}
+
+	return time.Time{}, errors.Errorf("%q is not like yyyy-mm-ddTHH:MM", value)
+}
+
+// Proposal is a shift of the file that is going to be scheduled, unless it has a conflict or a problem.
+type Proposal struct {
+	Shift model.Shift
+	// Conflicts is the ids of the shifts of the room that overlap the shift in its track.
+	Conflicts []int
+	// Problem is why the shift can't be scheduled otherwise, like when it overlaps another shift of the file.
+	Problem string
+}
+
+// Valid reports whether the shift of the proposal can be scheduled.
+func (p Proposal) Valid() bool {
+	return len(p.Conflicts) == 0 && p.Problem == ""
+}
+
+// Importer previews and schedules the shifts of a file.
+type Importer struct {
+	ShiftRepo model.ShiftRepo
+}
+
+// Preview checks the shifts against the shifts of their room and each other. The shifts must start after now.
+func (i *Importer) Preview(shifts []model.Shift, now time.Time) ([]Proposal, error) {
+	res := make([]Proposal, 0, len(shifts))
+
+	for n, shift := range shifts {
+		proposal := Proposal{Shift: shift, Conflicts: make([]int, 0), Problem: ""}
+		end := *shift.PlannedEndTime
+
+		switch {
+		case !shift.StartTime.Before(end):
+			proposal.Problem = "it doesn't start before it ends"
+		case !shift.StartTime.After(now):
+			proposal.Problem = "it doesn't start in the future"
+		}
+
+		for m, other := range res[:n] {
+			if other.Valid() && other.Shift.Track == shift.Track &&
+				other.Shift.StartTime.Before(end) && shift.StartTime.Before(*other.Shift.PlannedEndTime) {
+				proposal.Problem = fmt.Sprintf("it overlaps shift %d of the file", m+1)
+			}
+		}
+
+		overlapping, err := i.ShiftRepo.Overlapping(shift.RoomID, shift.StartTime, end)
+		if err != nil {
+			return nil, errors.Wrap(err, "error getting overlapping shifts")
+		}
+
+		for _, other := range model.InTrack(overlapping, shift.Track) {
+			// Shifts without an end are ended by a person and are not taken as a conflict.
+			if other.EndTime != nil || other.PlannedEndTime != nil {
+				proposal.Conflicts = append(proposal.Conflicts, other.ID)
+			}
+		}
+
+		res = append(res, proposal)
+	}
+
+	return res, nil
+}
+
+// Commit schedules the shifts of the valid proposals in one transaction and returns how many of them are scheduled.
+// No shift is scheduled if one of them fails.
+func (i *Importer) Commit(proposals []Proposal) (int, error) {
+	shifts := make([]model.Shift, 0, len(proposals))
+
+	for _, proposal := range proposals {
+		if proposal.Valid() {
+			shifts = append(shifts, proposal.Shift)
+		}
+	}
+
+	if len(shifts) == 0 {
+		return 0, nil
+	}
+
+	if err := i.ShiftRepo.CreateAll(shifts); err != nil {
+		return 0, errors.Wrap(err, "error saving shifts")
+	}
+
+	return len(shifts), nil
+}
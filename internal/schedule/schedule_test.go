@@ -0,0 +1,298 @@
+package schedule_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata" // Imported for the time zones of the tests
+
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/model"
+	"github.com/snapp-incubator/matrix-on-call-bot/internal/schedule"
+)
+
+const (
+	roomID = "!room:example.com"
+	sender = "@sender:example.com"
+)
+
+func tehran(t *testing.T) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation("Asia/Tehran")
+	if err != nil {
+		t.Fatalf("error loading time zone: %s", err)
+	}
+
+	return loc
+}
+
+// expectedShift is the part of a parsed shift that the tests check.
+type expectedShift struct {
+	holders []model.ShiftHolder
+	track   string
+	start   time.Time
+	end     time.Time
+}
+
+func (e expectedShift) equal(shift model.Shift) bool {
+	if shift.RoomID != roomID || shift.Sender != sender || !shift.Pending || shift.Track != e.track ||
+		!shift.StartTime.Equal(e.start) || shift.EndTime != nil || !shift.PlannedEndTime.Equal(e.end) ||
+		len(shift.Holders) != len(e.holders) {
+		return false
+	}
+
+	for i, holder := range e.holders {
+		if shift.Holders[i].Holder != holder.Holder || shift.Holders[i].Tier != holder.Tier {
+			return false
+		}
+	}
+
+	return true
+}
+
+// nolint: funlen
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	loc := tehran(t)
+	at := func(day, hour int) time.Time {
+		return time.Date(2022, 10, day, hour, 0, 0, 0, loc)
+	}
+
+	a := model.ShiftHolder{Holder: "@a:example.com", Tier: model.TierPrimary}
+	b := model.ShiftHolder{Holder: "@b:example.com", Tier: model.TierSecondary}
+	c := model.ShiftHolder{Holder: "@c:example.com", Tier: model.TierPrimary}
+
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected []expectedShift
+		err      error
+	}{
+		{
+			name: "csv",
+			file: "plan.CSV",
+			content: strings.Join([]string{
+				"Start, End, Holder, Tier, Track",
+				"2022-10-18 09:00, 2022-10-18 17:00, @a:example.com,,",
+				"2022-10-18 09:00, 2022-10-18 17:00, @b:example.com, secondary,",
+				"2022-10-17T09:00, 2022-10-17T17:00, @a:example.com @c:example.com,, database",
+			}, "\n"),
+			expected: []expectedShift{
+				{holders: []model.ShiftHolder{a, c}, track: "database", start: at(17, 9), end: at(17, 17)},
+				{holders: []model.ShiftHolder{a, b}, track: model.DefaultTrack, start: at(18, 9), end: at(18, 17)},
+			},
+		},
+		{
+			name: "calendar",
+			file: "plan.ics",
+			content: strings.Join([]string{
+				"BEGIN:VCALENDAR",
+				"BEGIN:VEVENT",
+				"SUMMARY:@a:example.com and @b:example.com (secondary)",
+				"DTSTART:20221018T090000", "DTEND:20221018T170000",
+				"END:VEVENT",
+				"BEGIN:VEVENT",
+				"SUMMARY:@c:example.com (boss)",
+				"DESCRIPTION:track=database",
+				"DTSTART:20221017T090000", "DTEND:20221017T170000",
+				"END:VEVENT",
+				"END:VCALENDAR",
+			}, "\r\n"),
+			expected: []expectedShift{
+				{holders: []model.ShiftHolder{c}, track: "database", start: at(17, 9), end: at(17, 17)},
+				{holders: []model.ShiftHolder{a, b}, track: model.DefaultTrack, start: at(18, 9), end: at(18, 17)},
+			},
+		},
+		{
+			name:    "unknown format",
+			file:    "plan.txt",
+			content: "start,end,holder",
+			err:     schedule.ErrUnknownFormat,
+		},
+		{
+			name:    "no shifts",
+			file:    "plan.csv",
+			content: "start,end,holder",
+			err:     schedule.ErrNoShifts,
+		},
+		{
+			name:    "invalid holder",
+			file:    "plan.csv",
+			content: "start,end,holder\n2022-10-18 09:00,2022-10-18 17:00,a",
+			err:     errors.New("invalid holder a in line 2"),
+		},
+		{
+			name:    "invalid tier",
+			file:    "plan.csv",
+			content: "start,end,holder,tier\n2022-10-18 09:00,2022-10-18 17:00,@a:example.com,boss",
+			err:     errors.New("invalid tier boss in line 2"),
+		},
+		{
+			name:    "missing column",
+			file:    "plan.csv",
+			content: "start,holder\n2022-10-18 09:00,@a:example.com",
+			err:     errors.New("missing end column"),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := schedule.Parse(test.file, []byte(test.content), roomID, sender, loc)
+			if test.err != nil {
+				if err == nil || (!errors.Is(err, test.err) && err.Error() != test.err.Error()) {
+					t.Errorf("expected %s, got %v", test.err, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if len(got) != len(test.expected) {
+				t.Fatalf("expected %d shifts, got %+v", len(test.expected), got)
+			}
+
+			for i, expected := range test.expected {
+				if !expected.equal(got[i]) {
+					t.Errorf("expected %+v at %d, got %+v", expected, i, got[i])
+				}
+			}
+		})
+	}
+}
+
+// shiftRepo is a shift repository with the given shifts of the room.
+type shiftRepo struct {
+	model.ShiftRepo
+	shifts []model.Shift
+}
+
+func (s shiftRepo) Overlapping(_ string, from time.Time, to time.Time) ([]model.Shift, error) {
+	res := make([]model.Shift, 0)
+
+	for _, shift := range s.shifts {
+		if shift.StartTime.Before(to) && (shift.EndTime == nil || shift.EndTime.After(from)) {
+			res = append(res, shift)
+		}
+	}
+
+	return res, nil
+}
+
+// nolint: funlen
+func TestPreview(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2022, 10, 18, 12, 0, 0, 0, time.UTC)
+	at := func(day, hour int) *time.Time {
+		res := time.Date(2022, 10, day, hour, 0, 0, 0, time.UTC)
+
+		return &res
+	}
+
+	shift := func(id int, track string, start, end *time.Time) model.Shift {
+		return model.Shift{ID: id, RoomID: roomID, Track: track, StartTime: *start, EndTime: nil, PlannedEndTime: end}
+	}
+
+	importer := schedule.Importer{ShiftRepo: shiftRepo{
+		shifts: []model.Shift{
+			shift(1, model.DefaultTrack, at(20, 9), at(20, 17)),
+			// An ongoing shift without an end is ended by a person.
+			{ID: 2, RoomID: roomID, Track: model.DefaultTrack, StartTime: *at(18, 9), EndTime: nil},
+		},
+	}}
+
+	type expectedProposal struct {
+		conflicts []int
+		problem   string
+	}
+
+	tests := []struct {
+		name     string
+		shifts   []model.Shift
+		expected []expectedProposal
+	}{
+		{
+			name:     "valid",
+			shifts:   []model.Shift{shift(0, model.DefaultTrack, at(19, 9), at(19, 17))},
+			expected: []expectedProposal{{conflicts: []int{}}},
+		},
+		{
+			name:     "conflict",
+			shifts:   []model.Shift{shift(0, model.DefaultTrack, at(20, 16), at(20, 20))},
+			expected: []expectedProposal{{conflicts: []int{1}}},
+		},
+		{
+			name:     "conflict in another track",
+			shifts:   []model.Shift{shift(0, "database", at(20, 16), at(20, 20))},
+			expected: []expectedProposal{{conflicts: []int{}}},
+		},
+		{
+			name: "overlap in the file",
+			shifts: []model.Shift{
+				shift(0, model.DefaultTrack, at(19, 9), at(19, 17)),
+				shift(0, model.DefaultTrack, at(19, 16), at(19, 20)),
+				shift(0, "database", at(19, 16), at(19, 20)),
+			},
+			expected: []expectedProposal{
+				{conflicts: []int{}},
+				{conflicts: []int{}, problem: "it overlaps shift 1 of the file"},
+				{conflicts: []int{}},
+			},
+		},
+		{
+			name:     "past start",
+			shifts:   []model.Shift{shift(0, model.DefaultTrack, at(18, 12), at(18, 17))},
+			expected: []expectedProposal{{conflicts: []int{}, problem: "it doesn't start in the future"}},
+		},
+		{
+			name:     "start not before end",
+			shifts:   []model.Shift{shift(0, model.DefaultTrack, at(19, 17), at(19, 17))},
+			expected: []expectedProposal{{conflicts: []int{}, problem: "it doesn't start before it ends"}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := importer.Preview(test.shifts, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if len(got) != len(test.expected) {
+				t.Fatalf("expected %d proposals, got %+v", len(test.expected), got)
+			}
+
+			for i, expected := range test.expected {
+				if got[i].Problem != expected.problem || len(got[i].Conflicts) != len(expected.conflicts) {
+					t.Errorf("expected %+v at %d, got %+v", expected, i, got[i])
+
+					continue
+				}
+
+				for j, conflict := range expected.conflicts {
+					if got[i].Conflicts[j] != conflict {
+						t.Errorf("expected %+v at %d, got %+v", expected, i, got[i])
+					}
+				}
+
+				if got[i].Valid() != (len(expected.conflicts) == 0 && expected.problem == "") {
+					t.Errorf("expected %+v to be valid only without conflicts and problems", got[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS rotations;
\ No newline at end of file
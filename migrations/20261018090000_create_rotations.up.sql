@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS rotations (
+    id INT NOT NULL AUTO_INCREMENT,
+    room_id VARCHAR(500) NOT NULL,
+    sender TEXT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    roster TEXT NOT NULL,
+    period BIGINT NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    next_handoff TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (room_id) REFERENCES rooms(id)
+);
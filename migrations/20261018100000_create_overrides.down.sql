@@ -0,0 +1 @@
+DROP TABLE IF EXISTS overrides;
\ No newline at end of file
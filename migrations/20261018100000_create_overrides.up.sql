@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS overrides (
+    id INT NOT NULL AUTO_INCREMENT,
+    shift_id INT NOT NULL,
+    room_id VARCHAR(500) NOT NULL,
+    sender TEXT NOT NULL,
+    holder VARCHAR(100) NOT NULL,
+    covered VARCHAR(100) NOT NULL,
+    start_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    end_time TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (shift_id) REFERENCES shifts(id),
+    FOREIGN KEY (room_id) REFERENCES rooms(id)
+);
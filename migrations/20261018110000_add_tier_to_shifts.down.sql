@@ -0,0 +1 @@
+ALTER TABLE shifts DROP COLUMN tier;
\ No newline at end of file
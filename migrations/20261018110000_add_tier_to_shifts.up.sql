@@ -0,0 +1 @@
+ALTER TABLE shifts ADD COLUMN tier VARCHAR(20) NOT NULL DEFAULT 'primary';
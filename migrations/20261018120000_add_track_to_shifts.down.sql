@@ -0,0 +1 @@
+ALTER TABLE shifts DROP COLUMN track;
\ No newline at end of file
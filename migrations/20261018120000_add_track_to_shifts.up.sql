@@ -0,0 +1 @@
+ALTER TABLE shifts ADD COLUMN track VARCHAR(100) NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE rotations DROP COLUMN track;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE rotations ADD COLUMN track VARCHAR(100) NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE shifts DROP COLUMN planned_end_time, DROP COLUMN pending;
\ No newline at end of file
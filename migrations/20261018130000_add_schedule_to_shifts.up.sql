@@ -0,0 +1,3 @@
+ALTER TABLE shifts
+    ADD COLUMN planned_end_time TIMESTAMP NULL DEFAULT NULL,
+    ADD COLUMN pending BOOLEAN NOT NULL DEFAULT FALSE;
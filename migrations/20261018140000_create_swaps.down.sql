@@ -0,0 +1 @@
+DROP TABLE IF EXISTS swaps;
\ No newline at end of file
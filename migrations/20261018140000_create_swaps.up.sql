@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS swaps (
+    id INT NOT NULL AUTO_INCREMENT,
+    room_id VARCHAR(500) NOT NULL,
+    shift_id INT NOT NULL,
+    requester VARCHAR(100) NOT NULL,
+    holder VARCHAR(100) NOT NULL,
+    target VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    event_id VARCHAR(255) NOT NULL DEFAULT '',
+    new_shift_id INT NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (room_id) REFERENCES rooms(id),
+    FOREIGN KEY (shift_id) REFERENCES shifts(id),
+    FOREIGN KEY (new_shift_id) REFERENCES shifts(id)
+);
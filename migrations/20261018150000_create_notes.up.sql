@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS notes (
+    id INT NOT NULL AUTO_INCREMENT,
+    shift_id INT NOT NULL,
+    sender TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (shift_id) REFERENCES shifts(id)
+);
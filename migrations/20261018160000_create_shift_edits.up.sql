@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS shift_edits (
+    id INT NOT NULL AUTO_INCREMENT,
+    shift_id INT NOT NULL,
+    sender TEXT NOT NULL,
+    field VARCHAR(32) NOT NULL,
+    old_value TEXT NOT NULL,
+    new_value TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (shift_id) REFERENCES shifts(id)
+);
@@ -0,0 +1 @@
+ALTER TABLE shifts DROP COLUMN cancelled_at, DROP COLUMN cancelled_by;
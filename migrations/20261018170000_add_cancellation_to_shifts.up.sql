@@ -0,0 +1,3 @@
+ALTER TABLE shifts
+    ADD COLUMN cancelled_at TIMESTAMP NULL DEFAULT NULL,
+    ADD COLUMN cancelled_by VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE follow_ups DROP COLUMN cancelled_at;
@@ -0,0 +1 @@
+ALTER TABLE follow_ups ADD COLUMN cancelled_at TIMESTAMP NULL DEFAULT NULL;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS shift_holders (
+    id INT NOT NULL AUTO_INCREMENT,
+    shift_id INT NOT NULL,
+    holder VARCHAR(100) NOT NULL,
+    tier VARCHAR(20) NOT NULL DEFAULT 'primary',
+    PRIMARY KEY (id),
+    FOREIGN KEY (shift_id) REFERENCES shifts(id)
+);
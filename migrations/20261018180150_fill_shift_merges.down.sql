@@ -0,0 +1 @@
+DELETE FROM shift_merges;
@@ -0,0 +1,13 @@
+-- Every shift that is not cancelled is merged into the first shift of the same room, track and start time. The
+-- cancelled shifts are kept as they are.
+INSERT INTO shift_merges (shift_id, merged_id)
+SELECT shifts.id, COALESCE(merged.id, shifts.id)
+FROM shifts
+LEFT JOIN (
+    SELECT MIN(id) AS id, room_id, track, start_time
+    FROM shifts
+    WHERE cancelled_at IS NULL
+    GROUP BY room_id, track, start_time
+) AS merged
+ON shifts.cancelled_at IS NULL
+    AND shifts.room_id = merged.room_id AND shifts.track = merged.track AND shifts.start_time = merged.start_time;
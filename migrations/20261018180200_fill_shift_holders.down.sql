@@ -0,0 +1,5 @@
+-- The shifts table has a single holder, so only one of the holders of the shifts with several holders is kept and the
+-- rest of them are lost. The merged shifts are not split again either, see 20261018180300_merge_shift_end_times.
+UPDATE shifts
+JOIN shift_holders ON shifts.id = shift_holders.shift_id
+SET shifts.holders = shift_holders.holder, shifts.tier = shift_holders.tier;
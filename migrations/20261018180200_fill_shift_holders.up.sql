@@ -0,0 +1,6 @@
+INSERT INTO shift_holders (shift_id, holder, tier)
+SELECT shift_merges.merged_id, shifts.holders, shifts.tier
+FROM shifts
+JOIN shift_merges ON shifts.id = shift_merges.shift_id
+WHERE shifts.holders IS NOT NULL
+ORDER BY shifts.id;
@@ -0,0 +1,11 @@
+-- The merge of the duplicated shifts can't be undone, so this migration has no down migration.
+UPDATE shifts
+JOIN (
+    SELECT shift_merges.merged_id,
+        CASE WHEN COUNT(*) = COUNT(merged.end_time) THEN MAX(merged.end_time) END AS end_time
+    FROM shift_merges
+    JOIN shifts AS merged ON merged.id = shift_merges.shift_id
+    GROUP BY shift_merges.merged_id
+) AS ends
+ON shifts.id = ends.merged_id
+SET shifts.end_time = ends.end_time;
@@ -0,0 +1,4 @@
+-- The merge of the duplicated shifts can't be undone, so this migration has no down migration.
+UPDATE follow_ups
+JOIN shift_merges ON follow_ups.shift_id = shift_merges.shift_id
+SET follow_ups.shift_id = shift_merges.merged_id;
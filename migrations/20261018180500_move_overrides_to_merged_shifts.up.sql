@@ -0,0 +1,4 @@
+-- The merge of the duplicated shifts can't be undone, so this migration has no down migration.
+UPDATE overrides
+JOIN shift_merges ON overrides.shift_id = shift_merges.shift_id
+SET overrides.shift_id = shift_merges.merged_id;
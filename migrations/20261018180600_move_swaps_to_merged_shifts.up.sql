@@ -0,0 +1,4 @@
+-- The merge of the duplicated shifts can't be undone, so this migration has no down migration.
+UPDATE swaps
+JOIN shift_merges ON swaps.shift_id = shift_merges.shift_id
+SET swaps.shift_id = shift_merges.merged_id;
@@ -0,0 +1,4 @@
+-- The merge of the duplicated shifts can't be undone, so this migration has no down migration.
+UPDATE notes
+JOIN shift_merges ON notes.shift_id = shift_merges.shift_id
+SET notes.shift_id = shift_merges.merged_id;
@@ -0,0 +1,4 @@
+-- The merge of the duplicated shifts can't be undone, so this migration has no down migration.
+UPDATE shift_edits
+JOIN shift_merges ON shift_edits.shift_id = shift_merges.shift_id
+SET shift_edits.shift_id = shift_merges.merged_id;
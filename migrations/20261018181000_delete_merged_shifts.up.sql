@@ -0,0 +1,5 @@
+-- The merge of the duplicated shifts can't be undone, so this migration has no down migration.
+DELETE shifts
+FROM shifts
+JOIN shift_merges ON shifts.id = shift_merges.shift_id
+WHERE shifts.id <> shift_merges.merged_id;
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS shift_merges (
+    shift_id INT NOT NULL,
+    merged_id INT NOT NULL,
+    PRIMARY KEY (shift_id)
+);
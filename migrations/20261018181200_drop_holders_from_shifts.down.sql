@@ -0,0 +1,2 @@
+-- The holders are filled back by 20261018180200_fill_shift_holders.down.sql, which keeps one holder of each shift.
+ALTER TABLE shifts ADD COLUMN holders VARCHAR(100), ADD COLUMN tier VARCHAR(20) NOT NULL DEFAULT 'primary';
@@ -0,0 +1 @@
+ALTER TABLE shifts DROP COLUMN holders, DROP COLUMN tier;
@@ -0,0 +1 @@
+ALTER TABLE rooms DROP COLUMN start_reminder, DROP COLUMN end_reminder;
@@ -0,0 +1,3 @@
+ALTER TABLE rooms
+    ADD COLUMN start_reminder BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN end_reminder BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS reminders (
+    id INT NOT NULL AUTO_INCREMENT,
+    shift_id INT NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY (shift_id, kind),
+    FOREIGN KEY (shift_id) REFERENCES shifts(id)
+);
@@ -0,0 +1 @@
+ALTER TABLE rooms DROP COLUMN max_shift_duration;
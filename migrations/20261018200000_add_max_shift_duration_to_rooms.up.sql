@@ -0,0 +1 @@
+ALTER TABLE rooms ADD COLUMN max_shift_duration BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE shifts DROP COLUMN ended_by;
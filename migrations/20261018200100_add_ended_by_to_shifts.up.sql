@@ -0,0 +1 @@
+ALTER TABLE shifts ADD COLUMN ended_by VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS absences (
+    id INT NOT NULL AUTO_INCREMENT,
+    room_id VARCHAR(500) NOT NULL,
+    holder VARCHAR(100) NOT NULL,
+    start_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    end_time TIMESTAMP NULL DEFAULT NULL,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX (holder),
+    FOREIGN KEY (room_id) REFERENCES rooms(id)
+);
@@ -0,0 +1 @@
+ALTER TABLE rooms ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS preferences (
+    user_id VARCHAR(100) NOT NULL,
+    time_zone VARCHAR(64) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id)
+);
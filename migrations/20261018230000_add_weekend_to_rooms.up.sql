@@ -0,0 +1 @@
+ALTER TABLE rooms ADD COLUMN weekend VARCHAR(100) NOT NULL DEFAULT 'thursday,friday';
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS holidays (
+    id INT NOT NULL AUTO_INCREMENT,
+    room_id VARCHAR(500) NOT NULL DEFAULT '',
+    date DATE NOT NULL,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE (room_id, date)
+);
@@ -0,0 +1 @@
+ALTER TABLE rooms DROP COLUMN working_hours;
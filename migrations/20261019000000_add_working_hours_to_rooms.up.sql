@@ -0,0 +1 @@
+ALTER TABLE rooms ADD COLUMN working_hours VARCHAR(20) NOT NULL DEFAULT '09:00-17:00';
@@ -0,0 +1 @@
+ALTER TABLE rooms ADD COLUMN rates VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS calendar_tokens (
+    token VARCHAR(64) NOT NULL,
+    room_id VARCHAR(500) NOT NULL DEFAULT '',
+    user_id VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (token),
+    UNIQUE (room_id, user_id)
+);
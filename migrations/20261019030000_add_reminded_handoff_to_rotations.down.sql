@@ -0,0 +1 @@
+ALTER TABLE rotations DROP COLUMN reminded_handoff;
@@ -0,0 +1 @@
+ALTER TABLE rotations ADD COLUMN reminded_handoff TIMESTAMP NULL DEFAULT NULL;